@@ -15,16 +15,74 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/v1/check/{card_id}": {
+        "/api/v1/achievements": {
+            "get": {
+                "description": "Get every achievement, with the rule a settled card has to meet to unlock it. Every field set on a rule has to hold. When someone unlocks an achievement an ` + "`" + `ACH` + "`" + ` message is sent to every client on the websocket.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "achievements"
+                ],
+                "summary": "List every achievement which can be unlocked",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/achievements.Achievement"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/achievements/me": {
             "get": {
-                "description": "Check your card to see if you won and claim your wins.",
+                "description": "Get every achievement you have unlocked, with when you unlocked it and the card and game which did it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "achievements"
+                ],
+                "summary": "List the achievements you have unlocked",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.UserAchievementResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/cancel/{card_id}": {
+            "post": {
+                "description": "Cancel every game on your card which hasn't started drawing yet and refund the stake for them. Games which have already been drawn, or are being drawn, are kept on the card.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "cards"
                 ],
-                "summary": "Check your card to see if you won",
+                "summary": "Cancel the games on your card which haven't started",
                 "parameters": [
                     {
                         "type": "integer",
@@ -38,7 +96,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.CheckCardResponse"
+                            "$ref": "#/definitions/api.CancelCardResponse"
                         }
                     },
                     "400": {
@@ -53,6 +111,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.APIError"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -62,9 +126,36 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/picks": {
+        "/api/v1/challenges": {
+            "get": {
+                "description": "Get every challenge you have made or been sent, newest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "challenges"
+                ],
+                "summary": "List your challenges",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.ChallengeResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    }
+                }
+            },
             "post": {
-                "description": "Give us your numbers so you can enjoy the number of games you specify. There are some rules:\n- You can only pick numbers between ` + "`" + `1` + "`" + ` and ` + "`" + `80` + "`" + `.\n- You can only pick ` + "`" + `1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 15, 20, 40` + "`" + ` numbers per game.\n- You can only play ` + "`" + `1, 2, 3, 4, 5, 10, 20, 50, 100` + "`" + ` number of games.",
+                "description": "Challenge another user to pick numbers for the same game, each of you putting up ` + "`" + `stake` + "`" + `. You submit your selection now and they submit theirs when they accept, using the same number of picks. Whoever does better takes the pot of both stakes, and if you draw you each get your stake back.\n- With the ` + "`" + `MATCHES` + "`" + ` rule whoever matches more numbers wins.\n- With the ` + "`" + `PRIZE` + "`" + ` rule whoever's selection would win the bigger prize as a card wins.\n\nThe challenge is on ` + "`" + `game_num` + "`" + `, or the next game open for betting if it's left out. It has to be accepted before that game's draw starts, otherwise it expires and your stake is refunded. The selection and stake follow the same rules as placing picks, and both of your responsible gambling limits apply.",
                 "consumes": [
                     "application/json"
                 ],
@@ -72,17 +163,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "picks"
+                    "challenges"
                 ],
-                "summary": "Place your picks for the next Keno game",
+                "summary": "Challenge another user to a head-to-head on a game",
                 "parameters": [
                     {
-                        "description": "Your picks for the next selected games",
-                        "name": "picks",
+                        "type": "string",
+                        "description": "Unique key for the request, retries with the same key replay the first response instead of placing another card",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Who to challenge and your selection",
+                        "name": "challenge",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.PickRequest"
+                            "$ref": "#/definitions/api.ChallengeRequest"
                         }
                     }
                 ],
@@ -90,7 +187,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.PickResponse"
+                            "$ref": "#/definitions/api.ChallengeResponse"
                         }
                     },
                     "400": {
@@ -99,6 +196,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.APIError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -108,36 +217,67 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/ws": {
+        "/api/v1/challenges/{challenge_id}": {
             "get": {
-                "description": "When a game is calculated and started, this endpoint will stream the game to the client. This will include all the picks which the client will have to display over 1.5 minutes for the proper effect.",
+                "description": "Get a challenge you have made or been sent.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "games"
+                    "challenges"
                 ],
-                "summary": "Stream the current game",
+                "summary": "Get one of your challenges",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Connection: Upgrade",
-                        "name": "Connection",
-                        "in": "header",
+                        "type": "integer",
+                        "description": "Challenge ID",
+                        "name": "challenge_id",
+                        "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.ChallengeResponse"
+                        }
                     },
-                    {
-                        "type": "string",
-                        "description": "Upgrade: websocket",
-                        "name": "Upgrade",
-                        "in": "header",
-                        "required": true
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
                     },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Cancel a challenge which hasn't been accepted yet, either one you made or one you've been sent. The challenger's stake is refunded.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "challenges"
+                ],
+                "summary": "Withdraw or decline a challenge",
+                "parameters": [
                     {
-                        "type": "string",
-                        "description": "Sec-Websocket-Version: 13",
-                        "name": "Sec-Websocket-Version",
-                        "in": "header",
+                        "type": "integer",
+                        "description": "Challenge ID",
+                        "name": "challenge_id",
+                        "in": "path",
                         "required": true
                     }
                 ],
@@ -145,7 +285,25 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Message"
+                            "$ref": "#/definitions/api.ChallengeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
                         }
                     },
                     "500": {
@@ -156,62 +314,2724 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "api.APIError": {
-            "type": "object",
-            "properties": {
-                "message": {
-                    "type": "string"
-                }
-            }
-        },
-        "api.CheckCardResponse": {
-            "type": "object",
-            "properties": {
-                "amount": {
-                    "type": "integer"
-                }
-            }
         },
-        "api.PickRequest": {
-            "type": "object",
-            "properties": {
-                "number_games": {
-                    "type": "integer"
-                },
-                "picks": {
-                    "type": "array",
-                    "items": {
-                        "type": "integer"
+        "/api/v1/challenges/{challenge_id}/accept": {
+            "post": {
+                "description": "Accept a pending challenge by submitting your own selection, with the same number of picks as the challenger's. Your stake is taken when you accept, and the challenge has to be accepted before its game's draw starts.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "challenges"
+                ],
+                "summary": "Accept a challenge you have been sent",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Challenge ID",
+                        "name": "challenge_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key for the request, retries with the same key replay the first response instead of placing another card",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Your selection",
+                        "name": "selection",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.AcceptChallengeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.ChallengeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
                     }
-                },
-                "picks_per_game": {
-                    "type": "integer"
-                },
-                "price_per_game": {
-                    "type": "integer"
                 }
             }
         },
-        "api.PickResponse": {
-            "type": "object",
-            "properties": {
-                "card_id": {
-                    "type": "integer"
-                },
-                "last_game_num": {
-                    "type": "integer"
-                },
-                "selection": {
-                    "type": "array",
+        "/api/v1/check/{card_id}": {
+            "get": {
+                "description": "Check your card to see if you won and claim your wins. Winnings are paid automatically as each game is settled, the response lists the result of every game on the card which has been settled so far.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cards"
+                ],
+                "summary": "Check your card to see if you won",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Card ID",
+                        "name": "card_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.CheckCardResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/events": {
+            "get": {
+                "description": "The same game messages as the websocket, ` + "`" + `NEW` + "`" + `, ` + "`" + `PIC` + "`" + ` and ` + "`" + `CUR` + "`" + `, as Server-Sent Events for clients which can't use websockets. Each event's data is a message and its ID numbers the message.\n\nWhen reconnecting, send the ID of the last event received in the ` + "`" + `Last-Event-ID` + "`" + ` header, which EventSource does for you, to get the messages missed since. If they are too old to replay a ` + "`" + `CUR` + "`" + ` message is sent instead, like on a new connection. A comment is sent every 15 seconds to keep the connection open.\n\nAuthenticate the stream the same way as the websocket, with the ` + "`" + `Authorization` + "`" + ` header or a ` + "`" + `ticket` + "`" + `, to also get the messages meant just for you. Those events don't have an ID as they can't be replayed.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "games"
+                ],
+                "summary": "Stream the current game as Server-Sent Events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the last event received",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the last event received, if it can't be sent in the Last-Event-ID header",
+                        "name": "last_event_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Stream ticket, if the Authorization header can't be sent",
+                        "name": "ticket",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Message"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/favourites": {
+            "get": {
+                "description": "Get every set of numbers you have saved as a favourite.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "favourites"
+                ],
+                "summary": "List your saved favourite selections",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.FavouriteResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Save a named set of numbers so you can place cards with it later. You can pick between ` + "`" + `1` + "`" + ` and ` + "`" + `40` + "`" + ` different numbers from ` + "`" + `1` + "`" + ` to ` + "`" + `80` + "`" + `, and save up to ` + "`" + `20` + "`" + ` favourites.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "favourites"
+                ],
+                "summary": "Save a favourite selection",
+                "parameters": [
+                    {
+                        "description": "The favourite to save",
+                        "name": "favourite",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.SaveFavouriteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.FavouriteResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/favourites/{favourite_id}": {
+            "delete": {
+                "description": "Delete one of your saved favourites.",
+                "tags": [
+                    "favourites"
+                ],
+                "summary": "Delete a favourite selection",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Favourite ID",
+                        "name": "favourite_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/favourites/{favourite_id}/play": {
+            "post": {
+                "description": "Place a card on the next game open for betting using the numbers from one of your favourites. Every number in the favourite is played unless ` + "`" + `picks_per_game` + "`" + ` is smaller, in which case it's placed as a system entry. Favourites of a size which can't be played as a single card, like ` + "`" + `12` + "`" + ` numbers, can only be played as a system entry so ` + "`" + `picks_per_game` + "`" + ` has to be given for them. The card follows the same rules as placing picks.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "favourites"
+                ],
+                "summary": "Place a card with a favourite selection",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Unique key for the request, retries with the same key replay the first response instead of placing another card",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Favourite ID",
+                        "name": "favourite_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The stake and number of games",
+                        "name": "card",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.PlayFavouriteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.PickResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/games": {
+            "get": {
+                "description": "Get the games which have been drawn, newest first, a page at a time. Games can be narrowed down to a range of game numbers, and to those which started within a time range.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "games"
+                ],
+                "summary": "List past games",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page, starting from 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Games per page, up to 100",
+                        "name": "per_page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Lowest game number",
+                        "name": "from_game",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Highest game number",
+                        "name": "to_game",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only games which started at or after this time, in unix milliseconds",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only games which started before this time, in unix milliseconds",
+                        "name": "until",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.GamesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/games/current": {
+            "get": {
+                "description": "Get the state of the game being drawn, or the last one drawn while betting is open on the next. This is the same state the stream sends when it connects, for clients which can't keep a websocket open. ` + "`" + `server_time` + "`" + ` is when the state was read, so clients can allow for their clocks being off.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "games"
+                ],
+                "summary": "Get the current game",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.CurrentGameResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/games/{game_id}": {
+            "get": {
+                "description": "Get a game's drawn numbers, in the order they were drawn, along with when it started and finished and whether it's still drawing. Games which have drawn all their numbers never change, so are sent with long lived caching headers.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "games"
+                ],
+                "summary": "Get a game",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Game ID",
+                        "name": "game_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.GameResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/history": {
+            "get": {
+                "description": "Get the most recent things which have happened to your cards and subscriptions, newest first. This includes cancellations and every card a subscription placed or skipped.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "history"
+                ],
+                "summary": "Get your recent history",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.HistoryResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/leaderboards/{board}": {
+            "get": {
+                "description": "Get the top players on a leaderboard for a period. The leaderboards are worked out from every settled card, other than syndicate cards, and are updated after every game is settled.\n- ` + "`" + `BIGGEST_WIN` + "`" + ` is the biggest prize won on a single game.\n- ` + "`" + `BEST_RETURN` + "`" + ` is the percentage of what was staked that was won back, only players who have staked at least ` + "`" + `100` + "`" + ` in the period are ranked.\n- ` + "`" + `TEN_SPOT_HITS` + "`" + ` is how many times all ten numbers of a 10-spot selection were drawn.\n- ` + "`" + `NET_PROFIT` + "`" + ` is how much more was won than staked, which can be negative.\n\nThe period is one of ` + "`" + `DAILY` + "`" + `, ` + "`" + `WEEKLY` + "`" + ` or ` + "`" + `MONTHLY` + "`" + `, which start at midnight UTC on the current day, Monday or the 1st, or ` + "`" + `ALL_TIME` + "`" + ` which is the default.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "leaderboards"
+                ],
+                "summary": "Get one of the leaderboards",
+                "parameters": [
+                    {
+                        "enum": [
+                            "BIGGEST_WIN",
+                            "BEST_RETURN",
+                            "TEN_SPOT_HITS",
+                            "NET_PROFIT"
+                        ],
+                        "type": "string",
+                        "description": "Leaderboard",
+                        "name": "board",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "DAILY",
+                            "WEEKLY",
+                            "MONTHLY",
+                            "ALL_TIME"
+                        ],
+                        "type": "string",
+                        "description": "Period",
+                        "name": "period",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.LeaderboardResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/limits": {
+            "get": {
+                "description": "Get the limits you have set on yourself, how much of each loss limit you have used across every guild, and any limit changes waiting to take effect.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "limits"
+                ],
+                "summary": "Get your responsible gambling limits",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.LimitsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Set how much you can lose in a rolling day, week or 30 days, and the most a single card can cost. Leave a limit out to keep it as it is, or set it to ` + "`" + `0` + "`" + ` to remove it.\n\nLowering a limit takes effect straight away. Raising or removing one only takes effect after 24 hours.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "limits"
+                ],
+                "summary": "Set your responsible gambling limits",
+                "parameters": [
+                    {
+                        "description": "The limits to change",
+                        "name": "limits",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.SetLimitsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.LimitsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/limits/cool-off": {
+            "post": {
+                "description": "Stop yourself from placing any cards for between ` + "`" + `1` + "`" + ` and ` + "`" + `720` + "`" + ` hours. A cool off can be extended but not shortened, and subscriptions skip their games while it lasts.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "limits"
+                ],
+                "summary": "Take a break from placing cards",
+                "parameters": [
+                    {
+                        "description": "How long to cool off for",
+                        "name": "cool_off",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.CoolOffRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.LimitsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/limits/self-exclude": {
+            "post": {
+                "description": "Stop yourself from placing any cards for at least ` + "`" + `30` + "`" + ` days, or for good if ` + "`" + `days` + "`" + ` is ` + "`" + `0` + "`" + `. A self exclusion can be extended but not shortened, and every active subscription is cancelled.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "limits"
+                ],
+                "summary": "Exclude yourself from playing",
+                "parameters": [
+                    {
+                        "description": "How long to exclude yourself for",
+                        "name": "exclusion",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.SelfExcludeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.LimitsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/picks": {
+            "post": {
+                "description": "Give us your numbers so you can enjoy the number of games you specify. There are some rules:\n- You can only pick numbers between ` + "`" + `1` + "`" + ` and ` + "`" + `80` + "`" + `, and each number only once.\n- You can only pick ` + "`" + `1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 15, 20, 40` + "`" + ` numbers per game.\n- You can only play ` + "`" + `1, 2, 3, 4, 5, 10, 20, 50, 100` + "`" + ` number of games.\n- By default you can only stake ` + "`" + `1, 2, 3, 5, 10, 20, 25, 50, 100` + "`" + ` per game, and no more than ` + "`" + `2000` + "`" + ` for the whole card, the server's betting config can change these.\n- Set ` + "`" + `quick_pick` + "`" + ` and leave out ` + "`" + `picks` + "`" + ` to have the server pick your numbers for you, the response includes the numbers it picked.\n- For a system entry set ` + "`" + `system_size` + "`" + ` to how many numbers you're picking, up to ` + "`" + `20` + "`" + `. Every ` + "`" + `picks_per_game` + "`" + ` sized combination of them is played, up to ` + "`" + `1000` + "`" + ` combinations, and the price per game is charged for each one.\n- Each game has a cap on how much it could pay out across every card, once it's reached the game won't take any more bets.\n- Cards which would break your own responsible gambling limits are refused with a ` + "`" + `403` + "`" + `.\n\nBetting on a game closes as soon as its draw starts, so a card placed while a game is being drawn starts on the following game. The response says which game the card starts on and when that game is due to start.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "picks"
+                ],
+                "summary": "Place your picks for the next Keno game",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Unique key for the request, retries with the same key replay the first response instead of placing another card",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Your picks for the next selected games",
+                        "name": "picks",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.PickRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.PickResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/picks/batch": {
+            "post": {
+                "description": "Place up to ` + "`" + `20` + "`" + ` cards in one go, every card follows the same rules as placing a single card and they all start on the same game. Either every card is placed or none of them are, if any card is invalid the response lists the error for each one by its index in the request. Your own responsible gambling limits apply to the batch as a whole.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "picks"
+                ],
+                "summary": "Place several cards for the next Keno game at once",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Unique key for the request, retries with the same key replay the first response instead of placing another card",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "The cards to place",
+                        "name": "picks",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.BatchPickRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.BatchPickResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.BatchPickErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/rebet/{card_id}": {
+            "post": {
+                "description": "Place a new card on the next game open for betting with the same selection, stake and number of games as one of your existing cards. The card follows the same rules as placing picks.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "picks"
+                ],
+                "summary": "Place a card again",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Unique key for the request, retries with the same key replay the first response instead of placing another card",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Card ID",
+                        "name": "card_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.PickResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/stream-ticket": {
+            "post": {
+                "description": "Get a short lived ticket which authenticates the websocket or event stream, for clients which can't send the ` + "`" + `Authorization` + "`" + ` header when opening them. Pass it as the ` + "`" + `ticket` + "`" + ` query parameter within 30 seconds, each ticket can only be used once. The stream gets the messages meant for you in the guild the ticket was made in.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "games"
+                ],
+                "summary": "Get a ticket to open the game stream with",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.StreamTicketResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/subscriptions": {
+            "get": {
+                "description": "Get every subscription you have made, including ones which have stopped.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "List your subscriptions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.SubscriptionResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Subscribe to have a single game card placed for you with the same selection and stake on every game, or every ` + "`" + `every` + "`" + ` games, up to ` + "`" + `100` + "`" + `. The card follows the same rules as placing picks and is placed just before betting on the game closes.\n\nThe subscription keeps going until you cancel it, until the next card would take the total staked over ` + "`" + `budget` + "`" + `, or until the total won reaches ` + "`" + `win_threshold` + "`" + `. Leave either out to not have that limit. Every card placed, or skipped, is logged to your history.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Place the same card automatically every game",
+                "parameters": [
+                    {
+                        "description": "The card to place and when to stop",
+                        "name": "subscription",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.SubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.SubscriptionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/subscriptions/{subscription_id}": {
+            "delete": {
+                "description": "Stop a subscription from placing any more cards. Cards it has already placed are kept.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Cancel a subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "subscription_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.SubscriptionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/syndicates": {
+            "get": {
+                "description": "Get every syndicate which is still selling shares, oldest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "syndicates"
+                ],
+                "summary": "List the syndicates with shares for sale",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.SyndicateResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Set up a card to be bought between several players. The card follows the same rules as placing picks and its total cost is split into ` + "`" + `shares` + "`" + ` equal shares, so the total cost has to divide evenly between them. You buy ` + "`" + `buy_shares` + "`" + ` of them straight away, at least one.\n\nAnyone can buy the rest of the shares, and as soon as the last one is sold the card is placed on the next game open for betting. Whatever it wins is split between the members by how many shares they hold, rounded down, with anything left over going to whoever started the syndicate.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "syndicates"
+                ],
+                "summary": "Start a syndicate card which others can buy shares in",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Unique key for the request, retries with the same key replay the first response instead of placing another card",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "The card to place and how to split it",
+                        "name": "syndicate",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.SyndicateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.SyndicateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/syndicates/{syndicate_id}": {
+            "get": {
+                "description": "Get a syndicate along with how many shares each member holds and everything it has paid out so far.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "syndicates"
+                ],
+                "summary": "Get a syndicate with its members and payouts",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Syndicate ID",
+                        "name": "syndicate_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.SyndicateDetailResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Stop selling shares in a syndicate you started. Only syndicates which are still open can be cancelled, and every share sold is refunded.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "syndicates"
+                ],
+                "summary": "Cancel a syndicate which hasn't been placed",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Syndicate ID",
+                        "name": "syndicate_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.SyndicateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/syndicates/{syndicate_id}/shares": {
+            "post": {
+                "description": "Buy shares in a syndicate which is still open. Buying the last share places the syndicate's card on the next game open for betting, if the card is refused the syndicate is cancelled and every share is refunded. Your responsible gambling limits apply to the cost of the shares.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "syndicates"
+                ],
+                "summary": "Buy shares in a syndicate",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Syndicate ID",
+                        "name": "syndicate_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key for the request, retries with the same key replay the first response instead of placing another card",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "How many shares to buy",
+                        "name": "shares",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.BuySharesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.SyndicateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/tickets/{ticket_ref}": {
+            "get": {
+                "description": "Anyone with a ticket reference can see the card's selection and how it has done on every game settled so far, without signing in. References are signed, so forged or mistyped ones are rejected.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cards"
+                ],
+                "summary": "Look up a card by its ticket reference",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Ticket reference",
+                        "name": "ticket_ref",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.TicketResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/tournaments": {
+            "get": {
+                "description": "Get every tournament, latest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tournaments"
+                ],
+                "summary": "List the tournaments",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.TournamentResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Set up a tournament over the games from ` + "`" + `first_game_num` + "`" + ` up to, but not including, ` + "`" + `last_game_num` + "`" + `. Everyone who registers gets ` + "`" + `bankroll` + "`" + ` to place tournament cards with, and once the last game is settled the entry ranked first is paid the first of ` + "`" + `prizes` + "`" + `, second the second and so on.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tournaments"
+                ],
+                "summary": "Create a tournament, admins only",
+                "parameters": [
+                    {
+                        "description": "The tournament",
+                        "name": "tournament",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.TournamentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.TournamentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/tournaments/{tournament_id}/picks": {
+            "post": {
+                "description": "Place a card in a tournament you have registered for, paid for from your tournament bankroll. The card follows the same rules as placing picks, other than the game liability, and has to start and finish within the tournament's games. Tournament cards only win tournament points, which make up your score.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tournaments"
+                ],
+                "summary": "Place a tournament card",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Tournament ID",
+                        "name": "tournament_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key for the request, retries with the same key replay the first response instead of placing another card",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Your picks",
+                        "name": "picks",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.PickRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.PickResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/tournaments/{tournament_id}/register": {
+            "post": {
+                "description": "Enter a tournament and get its bankroll to place tournament cards with. You can register any time before its last game starts.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tournaments"
+                ],
+                "summary": "Register for a tournament",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Tournament ID",
+                        "name": "tournament_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandingResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/tournaments/{tournament_id}/standings": {
+            "get": {
+                "description": "Get every entry in a tournament ranked by how much their tournament cards have won. Entries on the same score are ranked by who has more bankroll left, then by who registered first. Scores are updated as each game is settled, and once the tournament has finished the ranks are final and include the prizes paid.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tournaments"
+                ],
+                "summary": "Get the standings of a tournament",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Tournament ID",
+                        "name": "tournament_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandingsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/ws": {
+            "get": {
+                "description": "When a game is calculated and started, this endpoint will stream the game to the client. This will include all the picks which the client will have to display over 1.5 minutes for the proper effect.\n\nPass your Discord token in the ` + "`" + `Authorization` + "`" + ` header to also get messages meant just for you. Clients which can't set headers, like browsers, can get a ticket from ` + "`" + `POST /api/v1/stream-ticket` + "`" + ` and pass it in the ` + "`" + `ticket` + "`" + ` query parameter instead. A ` + "`" + `CARD` + "`" + ` message confirms each card you place and a ` + "`" + `WIN` + "`" + ` message is sent for every card which wins something when a game is settled.\n\nMessages about players only come from the guild given in the ` + "`" + `X-Guild-Id` + "`" + ` header, or the guild the ticket was made in, or the default community if there isn't one. Anonymous sockets only get game updates.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "games"
+                ],
+                "summary": "Stream the current game",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Stream ticket, if the Authorization header can't be sent",
+                        "name": "ticket",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Connection: Upgrade",
+                        "name": "Connection",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Upgrade: websocket",
+                        "name": "Upgrade",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sec-Websocket-Version: 13",
+                        "name": "Sec-Websocket-Version",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Message"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.APIError"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "achievements.Achievement": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "rule": {
+                    "$ref": "#/definitions/achievements.Rule"
+                }
+            }
+        },
+        "achievements.Rule": {
+            "type": "object",
+            "properties": {
+                "game_modulo": {
+                    "description": "GameModulo and GameRemainder match games whose number leaves\nGameRemainder when divided by GameModulo, so a modulo of 1000 and a\nremainder of 0 matches games ending in 000",
+                    "type": "integer"
+                },
+                "game_remainder": {
+                    "type": "integer"
+                },
+                "games_played": {
+                    "description": "GamesPlayed is how many games the user has to have had settled, on\nany of their cards",
+                    "type": "integer"
+                },
+                "max_matches": {
+                    "type": "integer"
+                },
+                "min_matches": {
+                    "description": "MinMatches and MaxMatches bound how many of the card's numbers were\ndrawn",
+                    "type": "integer"
+                },
+                "min_prize": {
+                    "description": "MinPrize is the least the card has to have won on the game",
+                    "type": "integer"
+                },
+                "spots": {
+                    "description": "Spots is how many numbers the card plays per game",
+                    "type": "integer"
+                }
+            }
+        },
+        "api.APIError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "details": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.ErrorDetail"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.AcceptChallengeRequest": {
+            "type": "object",
+            "properties": {
+                "picks": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "quick_pick": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "api.BatchPickError": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "$ref": "#/definitions/api.APIError"
+                },
+                "index": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.BatchPickErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "details": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.ErrorDetail"
+                    }
+                },
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.BatchPickError"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.BatchPickRequest": {
+            "type": "object",
+            "properties": {
+                "cards": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.PickRequest"
+                    }
+                }
+            }
+        },
+        "api.BatchPickResponse": {
+            "type": "object",
+            "properties": {
+                "cards": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.PickResponse"
+                    }
+                }
+            }
+        },
+        "api.BuySharesRequest": {
+            "type": "object",
+            "properties": {
+                "shares": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.CancelCardResponse": {
+            "type": "object",
+            "properties": {
+                "card_id": {
+                    "type": "integer"
+                },
+                "last_game_num": {
+                    "type": "integer"
+                },
+                "refund": {
+                    "type": "integer"
+                },
+                "start_game_num": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.CardGameResult": {
+            "type": "object",
+            "properties": {
+                "game_id": {
+                    "type": "integer"
+                },
+                "matches": {
+                    "type": "integer"
+                },
+                "prize": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.ChallengeRequest": {
+            "type": "object",
+            "properties": {
+                "game_num": {
+                    "description": "GameNum is the game the challenge is on, the next game open for\nbetting if it's left out",
+                    "type": "integer"
+                },
+                "opponent": {
+                    "type": "string"
+                },
+                "picks": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "picks_per_game": {
+                    "type": "integer"
+                },
+                "quick_pick": {
+                    "type": "boolean"
+                },
+                "rule": {
+                    "type": "string"
+                },
+                "stake": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.ChallengeResponse": {
+            "type": "object",
+            "properties": {
+                "challenge_id": {
+                    "type": "integer"
+                },
+                "challenger": {
+                    "type": "string"
+                },
+                "challenger_matches": {
+                    "type": "integer"
+                },
+                "challenger_picks": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "game_id": {
+                    "type": "integer"
+                },
+                "opponent": {
+                    "type": "string"
+                },
+                "opponent_matches": {
+                    "type": "integer"
+                },
+                "opponent_picks": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "pot": {
+                    "type": "integer"
+                },
+                "rule": {
+                    "type": "string"
+                },
+                "stake": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "winner": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.CheckCardResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "games": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.CardGameResult"
+                    }
+                }
+            }
+        },
+        "api.CoolOffRequest": {
+            "type": "object",
+            "properties": {
+                "hours": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.CurrentGameResponse": {
+            "type": "object",
+            "properties": {
+                "end_time": {
+                    "type": "integer"
+                },
+                "game_id": {
+                    "type": "integer"
+                },
+                "next_game_time": {
+                    "type": "integer"
+                },
+                "phase": {
+                    "type": "string"
+                },
+                "picks": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "server_time": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.ErrorDetail": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.FavouriteResponse": {
+            "type": "object",
+            "properties": {
+                "favourite_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "selection": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "api.GameResponse": {
+            "type": "object",
+            "properties": {
+                "end_time": {
+                    "type": "integer"
+                },
+                "game_id": {
+                    "type": "integer"
+                },
+                "picks": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "start_time": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.GamesResponse": {
+            "type": "object",
+            "properties": {
+                "games": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.GameResponse"
+                    }
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "per_page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.HistoryResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "card_id": {
+                    "type": "integer"
+                },
+                "event": {
+                    "type": "string"
+                },
+                "game_id": {
+                    "type": "integer"
+                },
+                "note": {
+                    "type": "string"
+                },
+                "subscription_id": {
+                    "type": "integer"
+                },
+                "time": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.LeaderboardEntryResponse": {
+            "type": "object",
+            "properties": {
+                "rank": {
+                    "type": "integer"
+                },
+                "user": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.LeaderboardResponse": {
+            "type": "object",
+            "properties": {
+                "board": {
+                    "type": "string"
+                },
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.LeaderboardEntryResponse"
+                    }
+                },
+                "period": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.LimitResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "pending": {
+                    "type": "integer"
+                },
+                "pending_at": {
+                    "type": "integer"
+                },
+                "used": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.LimitsResponse": {
+            "type": "object",
+            "properties": {
+                "cool_off_until": {
+                    "type": "integer"
+                },
+                "daily_loss": {
+                    "$ref": "#/definitions/api.LimitResponse"
+                },
+                "excluded_until": {
+                    "type": "integer"
+                },
+                "max_stake": {
+                    "$ref": "#/definitions/api.LimitResponse"
+                },
+                "monthly_loss": {
+                    "$ref": "#/definitions/api.LimitResponse"
+                },
+                "weekly_loss": {
+                    "$ref": "#/definitions/api.LimitResponse"
+                }
+            }
+        },
+        "api.PickRequest": {
+            "type": "object",
+            "properties": {
+                "number_games": {
+                    "type": "integer"
+                },
+                "picks": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "picks_per_game": {
+                    "type": "integer"
+                },
+                "price_per_game": {
+                    "type": "integer"
+                },
+                "quick_pick": {
+                    "description": "QuickPick asks the server to pick the numbers, Picks is ignored and\nreplaced with a random selection of the right size.",
+                    "type": "boolean"
+                },
+                "system_size": {
+                    "description": "SystemSize turns the card into a system entry, Picks holds SystemSize\nnumbers and every PicksPerGame sized combination of them is played.",
+                    "type": "integer"
+                }
+            }
+        },
+        "api.PickResponse": {
+            "type": "object",
+            "properties": {
+                "card_id": {
+                    "type": "integer"
+                },
+                "combinations": {
+                    "type": "integer"
+                },
+                "last_game_num": {
+                    "type": "integer"
+                },
+                "selection": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "start_game_num": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "integer"
+                },
+                "ticket_ref": {
+                    "type": "string"
+                },
+                "total_cost": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.PlayFavouriteRequest": {
+            "type": "object",
+            "properties": {
+                "number_games": {
+                    "type": "integer"
+                },
+                "picks_per_game": {
+                    "type": "integer"
+                },
+                "price_per_game": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.SaveFavouriteRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "picks": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "api.SelfExcludeRequest": {
+            "type": "object",
+            "properties": {
+                "days": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.SetLimitsRequest": {
+            "type": "object",
+            "properties": {
+                "daily_loss": {
+                    "type": "integer"
+                },
+                "max_stake": {
+                    "type": "integer"
+                },
+                "monthly_loss": {
+                    "type": "integer"
+                },
+                "weekly_loss": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.StandingResponse": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "integer"
+                },
+                "prize": {
+                    "type": "integer"
+                },
+                "rank": {
+                    "type": "integer"
+                },
+                "score": {
+                    "type": "integer"
+                },
+                "user": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.StandingsResponse": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.StandingResponse"
+                    }
+                },
+                "tournament": {
+                    "$ref": "#/definitions/api.TournamentResponse"
+                }
+            }
+        },
+        "api.StreamTicketResponse": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "integer"
+                },
+                "ticket": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.SubscriptionRequest": {
+            "type": "object",
+            "properties": {
+                "budget": {
+                    "type": "integer"
+                },
+                "every": {
+                    "type": "integer"
+                },
+                "picks": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "picks_per_game": {
+                    "type": "integer"
+                },
+                "price_per_game": {
+                    "type": "integer"
+                },
+                "quick_pick": {
+                    "type": "boolean"
+                },
+                "system_size": {
+                    "type": "integer"
+                },
+                "win_threshold": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.SubscriptionResponse": {
+            "type": "object",
+            "properties": {
+                "budget": {
+                    "type": "integer"
+                },
+                "every": {
+                    "type": "integer"
+                },
+                "next_game_num": {
+                    "type": "integer"
+                },
+                "picks_per_game": {
+                    "type": "integer"
+                },
+                "price_per_game": {
+                    "type": "integer"
+                },
+                "selection": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "spent": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "subscription_id": {
+                    "type": "integer"
+                },
+                "win_threshold": {
+                    "type": "integer"
+                },
+                "won": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.SyndicateDetailResponse": {
+            "type": "object",
+            "properties": {
+                "card_id": {
+                    "type": "integer"
+                },
+                "members": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.SyndicateMember"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "number_games": {
+                    "type": "integer"
+                },
+                "payouts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.SyndicatePayoutResponse"
+                    }
+                },
+                "price_per_game": {
+                    "type": "integer"
+                },
+                "selection": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "share_price": {
+                    "type": "integer"
+                },
+                "shares": {
+                    "type": "integer"
+                },
+                "shares_sold": {
+                    "type": "integer"
+                },
+                "spots": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "syndicate_id": {
+                    "type": "integer"
+                },
+                "user": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.SyndicateMember": {
+            "type": "object",
+            "properties": {
+                "cost": {
+                    "type": "integer"
+                },
+                "shares": {
+                    "type": "integer"
+                },
+                "user": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.SyndicatePayoutResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "game_id": {
+                    "type": "integer"
+                },
+                "shares": {
+                    "type": "integer"
+                },
+                "user": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.SyndicateRequest": {
+            "type": "object",
+            "properties": {
+                "buy_shares": {
+                    "type": "integer"
+                },
+                "card": {
+                    "$ref": "#/definitions/api.PickRequest"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "shares": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.SyndicateResponse": {
+            "type": "object",
+            "properties": {
+                "card_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "number_games": {
+                    "type": "integer"
+                },
+                "price_per_game": {
+                    "type": "integer"
+                },
+                "selection": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "share_price": {
+                    "type": "integer"
+                },
+                "shares": {
+                    "type": "integer"
+                },
+                "shares_sold": {
+                    "type": "integer"
+                },
+                "spots": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "syndicate_id": {
+                    "type": "integer"
+                },
+                "user": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.TicketResponse": {
+            "type": "object",
+            "properties": {
+                "games": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.CardGameResult"
+                    }
+                },
+                "last_game_num": {
+                    "type": "integer"
+                },
+                "price_per_game": {
+                    "type": "integer"
+                },
+                "selection": {
+                    "type": "array",
                     "items": {
                         "type": "integer"
                     }
                 },
+                "spots": {
+                    "type": "integer"
+                },
                 "start_game_num": {
                     "type": "integer"
+                },
+                "ticket_ref": {
+                    "type": "string"
+                },
+                "total_cost": {
+                    "type": "integer"
+                },
+                "won": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.TournamentRequest": {
+            "type": "object",
+            "properties": {
+                "bankroll": {
+                    "type": "integer"
+                },
+                "first_game_num": {
+                    "type": "integer"
+                },
+                "last_game_num": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prizes": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "api.TournamentResponse": {
+            "type": "object",
+            "properties": {
+                "bankroll": {
+                    "type": "integer"
+                },
+                "first_game_num": {
+                    "type": "integer"
+                },
+                "last_game_num": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prizes": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                },
+                "tournament_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.UserAchievementResponse": {
+            "type": "object",
+            "properties": {
+                "achievement_id": {
+                    "type": "string"
+                },
+                "card_id": {
+                    "type": "integer"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "game_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "unlocked_at": {
+                    "type": "integer"
                 }
             }
         },
@@ -235,7 +3055,7 @@ var SwaggerInfo = &swag.Spec{
 	BasePath:         "",
 	Schemes:          []string{},
 	Title:            "TAB Keno API",
-	Description:      "This is a sample server for TAB Keno API.",
+	Description:      "This is a sample server for TAB Keno API.\nRequests are made in the default community unless the X-Guild-Id header names one of the guilds sharing the backend, which keeps its own cards, results and leaderboards.\nEvery response has an X-Request-Id header, which is kept from the request if it had one. Errors have a stable `code`, a `message`, the same `request_id` and, for invalid requests, `details` of each field which was wrong.",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
 }
@@ -0,0 +1,204 @@
+package achievements
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"keno/internal/models"
+	"os"
+
+	"gorm.io/gorm"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const AchievementsKey = "achievements"
+
+// Achievement is a badge users unlock when one of their settled cards meets
+// its Rule. Achievements are loaded from a config file so they can be changed
+// without touching the code.
+type Achievement struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Rule        Rule   `json:"rule"`
+}
+
+// Rule describes the settled card which unlocks an achievement. Every field
+// which is set has to hold for the rule to match, fields which aren't set
+// aren't checked.
+type Rule struct {
+	// Spots is how many numbers the card plays per game
+	Spots *uint8 `json:"spots,omitempty"`
+
+	// MinMatches and MaxMatches bound how many of the card's numbers were
+	// drawn
+	MinMatches *uint8 `json:"min_matches,omitempty"`
+	MaxMatches *uint8 `json:"max_matches,omitempty"`
+
+	// MinPrize is the least the card has to have won on the game
+	MinPrize *uint64 `json:"min_prize,omitempty"`
+
+	// GameModulo and GameRemainder match games whose number leaves
+	// GameRemainder when divided by GameModulo, so a modulo of 1000 and a
+	// remainder of 0 matches games ending in 000
+	GameModulo    uint64 `json:"game_modulo,omitempty"`
+	GameRemainder uint64 `json:"game_remainder,omitempty"`
+
+	// GamesPlayed is how many games the user has to have had settled, on
+	// any of their cards
+	GamesPlayed uint64 `json:"games_played,omitempty"`
+}
+
+// Matches reports whether the result meets the rule, gamesPlayed is how many
+// games the user has had settled including this one.
+func (r Rule) Matches(result models.CardResult, gamesPlayed uint64) bool {
+	if r.Spots != nil && result.Spots != *r.Spots {
+		return false
+	}
+
+	if r.MinMatches != nil && result.Matches < *r.MinMatches {
+		return false
+	}
+
+	if r.MaxMatches != nil && result.Matches > *r.MaxMatches {
+		return false
+	}
+
+	if r.MinPrize != nil && result.Prize < *r.MinPrize {
+		return false
+	}
+
+	if r.GameModulo > 0 && result.GameID%r.GameModulo != r.GameRemainder {
+		return false
+	}
+
+	return gamesPlayed >= r.GamesPlayed
+}
+
+// LoadAchievements reads the achievements from the JSON config file at path.
+// A missing file means there are no achievements.
+func LoadAchievements(path string) ([]Achievement, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		log.WithField("src", "achievements.LoadAchievements").Warn("No achievements config found")
+		return []Achievement{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var achievements []Achievement
+	if err := json.Unmarshal(data, &achievements); err != nil {
+		return nil, err
+	}
+
+	// Achievements are stored against their ID, so it has to be unique
+	ids := make(map[string]bool)
+	for _, achievement := range achievements {
+		if achievement.ID == "" || ids[achievement.ID] {
+			return nil, fmt.Errorf("achievement ID %q is empty or repeated", achievement.ID)
+		}
+		ids[achievement.ID] = true
+	}
+
+	return achievements, nil
+}
+
+// Checker unlocks achievements for the cards on each settled game.
+type Checker struct {
+	db           *gorm.DB
+	achievements []Achievement
+	notify       func(tenant string, msg models.Message)
+}
+
+// NewChecker returns a Checker for the achievements, notify is called with an
+// ACH message for every achievement unlocked, along with the tenant it was
+// unlocked in.
+func NewChecker(db *gorm.DB, achievements []Achievement, notify func(tenant string, msg models.Message)) *Checker {
+	return &Checker{
+		db:           db,
+		achievements: achievements,
+		notify:       notify,
+	}
+}
+
+// GameSettled checks every result on the game against the achievements, it is
+// meant to be registered as a settlement hook. Syndicate cards are skipped as
+// they belong to several users, and so are tournament cards and refunds for
+// games which were abandoned.
+func (c *Checker) GameSettled(game models.Game, results []models.CardResult) error {
+	if len(c.achievements) == 0 {
+		return nil
+	}
+
+	// Users play separately in each tenant, so are counted separately too
+	type player struct{ tenant, user string }
+	gamesPlayed := make(map[player]uint64)
+	var failed error
+	for _, result := range results {
+		if result.SyndicateID != 0 || result.TournamentID != 0 || result.Refund > 0 {
+			continue
+		}
+
+		key := player{result.Tenant, result.User}
+		played, ok := gamesPlayed[key]
+		if !ok {
+			count, err := models.CountGamesPlayed(models.ForTenant(c.db, result.Tenant), result.User)
+			if err != nil {
+				log.WithField("src", "achievements.GameSettled").WithError(err).Error("Error counting games played")
+				failed = errors.Join(failed, err)
+				continue
+			}
+
+			played = uint64(count)
+			gamesPlayed[key] = played
+		}
+
+		for _, achievement := range c.achievements {
+			if !achievement.Rule.Matches(result, played) {
+				continue
+			}
+
+			if err := c.unlock(achievement, result); err != nil {
+				failed = errors.Join(failed, err)
+			}
+		}
+	}
+
+	return failed
+}
+
+// unlock gives the user the achievement and tells everyone in the tenant if
+// they didn't already have it.
+func (c *Checker) unlock(achievement Achievement, result models.CardResult) error {
+	unlocked, err := models.UnlockAchievement(models.ForTenant(c.db, result.Tenant), models.UserAchievement{
+		AchievementID: achievement.ID,
+		CardID:        result.CardID,
+		GameID:        result.GameID,
+		User:          result.User,
+	})
+	if err != nil {
+		log.WithFields(log.Fields{
+			"src":         "achievements.unlock",
+			"achievement": achievement.ID,
+			"user":        result.User,
+		}).WithError(err).Error("Error unlocking achievement")
+		return err
+	}
+
+	if !unlocked {
+		return nil
+	}
+
+	c.notify(result.Tenant, models.GenerateMessage(models.AchievementMsg{
+		User:          result.User,
+		AchievementId: achievement.ID,
+		Name:          achievement.Name,
+		Description:   achievement.Description,
+		CardId:        result.CardID,
+		GameId:        result.GameID,
+	}))
+
+	return nil
+}
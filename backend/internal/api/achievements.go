@@ -0,0 +1,92 @@
+package api
+
+import (
+	"keno/internal/achievements"
+	"keno/internal/db"
+	"keno/internal/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// List Achievements
+// @Summary List every achievement which can be unlocked
+// @Description Get every achievement, with the rule a settled card has to meet to unlock it. Every field set on a rule has to hold. When someone unlocks an achievement an `ACH` message is sent to every client on the websocket.
+// @Tags achievements
+// @Produce json
+// @Success 200 {array} achievements.Achievement
+// @Failure 500 {object} APIError
+// @Router /api/v1/achievements [get]
+func ListAchievements(ctx *gin.Context) {
+	// Get the achievements from the context
+	list, ok := ctx.Get(achievements.AchievementsKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, list)
+}
+
+// Get Your Achievements
+// @Summary List the achievements you have unlocked
+// @Description Get every achievement you have unlocked, with when you unlocked it and the card and game which did it.
+// @Tags achievements
+// @Produce json
+// @Success 200 {array} UserAchievementResponse
+// @Failure 500 {object} APIError
+// @Router /api/v1/achievements/me [get]
+func GetUserAchievements(ctx *gin.Context) {
+	// Get the achievements from the context
+	list, ok := ctx.Get(achievements.AchievementsKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	// Get the database from the context
+	db, ok := ctx.Get(db.DbKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	unlocked, err := models.GetUserAchievements(db.(*gorm.DB), ctx.GetString(USER_ID_KEY))
+	if err != nil {
+		log.WithField("src", "api.GetUserAchievements").WithError(err).Error("Error getting achievements")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	// Achievements which have since been taken out of the config keep their ID
+	byId := make(map[string]achievements.Achievement)
+	for _, achievement := range list.([]achievements.Achievement) {
+		byId[achievement.ID] = achievement
+	}
+
+	resp := make([]UserAchievementResponse, 0, len(unlocked))
+	for _, achievement := range unlocked {
+		resp = append(resp, UserAchievementResponse{
+			AchievementId: achievement.AchievementID,
+			Name:          byId[achievement.AchievementID].Name,
+			Description:   byId[achievement.AchievementID].Description,
+			CardId:        achievement.CardID,
+			GameId:        achievement.GameID,
+			UnlockedAt:    achievement.CreatedAt.UnixMilli(),
+		})
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+type UserAchievementResponse struct {
+	AchievementId string `json:"achievement_id"`
+	Name          string `json:"name,omitempty"`
+	Description   string `json:"description,omitempty"`
+	CardId        uint64 `json:"card_id"`
+	GameId        uint64 `json:"game_id"`
+	UnlockedAt    int64  `json:"unlocked_at"`
+}
@@ -2,48 +2,193 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
+	"keno/internal/db"
+	"keno/internal/models"
+	"keno/internal/tenants"
+	"keno/internal/utils"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	USER_ID_KEY = "user_id"
+
+	// GUILD_HEADER picks the Discord guild a request is made in, requests
+	// without it are made in the default community
+	GUILD_HEADER = "X-Guild-Id"
+)
+
+var (
+	// Admins are the Discord user IDs allowed to use the admin endpoints
+	Admins []string
+
+	// Tenants are the guilds sharing the backend, keyed by guild ID
+	Tenants = map[string]tenants.Tenant{}
 )
 
-const USER_ID_KEY = "user_id"
+var (
+	errInvalidToken = errors.New("invalid token")
+	errInvalidGuild = errors.New("invalid guild")
+)
 
 func DiscordAuth(ctx *gin.Context) {
 
 	// Check the Authorization header for the token
 	authToken := ctx.GetHeader("Authorization")
 
+	userId, err := getDiscordUser(authToken)
+	if errors.Is(err, errInvalidToken) {
+		respondError(ctx, http.StatusUnauthorized, ErrInvalidToken)
+		return
+	}
+	if err != nil {
+		log.WithField("src", "api.DiscordAuth").WithError(err).Error("Error authenticating with Discord")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	tenant, err := getTenant(authToken, ctx.GetHeader(GUILD_HEADER))
+	if errors.Is(err, errInvalidGuild) {
+		respondError(ctx, http.StatusForbidden, ErrInvalidGuild)
+		return
+	}
+	if err != nil {
+		log.WithField("src", "api.DiscordAuth").WithError(err).Error("Error authenticating with Discord")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	// Scope the database to the tenant, so nothing from another guild can be
+	// seen or changed
+	if database, ok := ctx.Get(db.DbKey); ok {
+		ctx.Set(db.DbKey, models.ForTenant(database.(*gorm.DB), tenant))
+	}
+
+	ctx.Set(USER_ID_KEY, userId)
+	ctx.Next()
+}
+
+// Authenticate returns the Discord user the token belongs to and the tenant
+// for the guild, for APIs which don't go through DiscordAuth. It returns
+// ErrInvalidToken or ErrInvalidGuild if either isn't accepted.
+func Authenticate(authToken, guild string) (userId, tenant string, err error) {
+	userId, err = getDiscordUser(authToken)
+	if errors.Is(err, errInvalidToken) {
+		return "", "", ErrInvalidToken
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	tenant, err = getTenant(authToken, guild)
+	if errors.Is(err, errInvalidGuild) {
+		return "", "", ErrInvalidGuild
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	return userId, tenant, nil
+}
+
+// getDiscordUser returns the ID of the Discord user the token belongs to, or
+// errInvalidToken if Discord doesn't accept it.
+func getDiscordUser(authToken string) (string, error) {
 	// Make a GET request to the Discord API to get the user's info
 	client := &http.Client{}
 	req, _ := http.NewRequest("GET", "https://discord.com/api/users/@me", nil)
 	req.Header.Add("Authorization", authToken)
 	resp, err := client.Do(req)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"message": "Invalid token"})
-		return
+		return "", errInvalidToken
 	}
+	defer resp.Body.Close()
 
 	// Check the response
 	if resp.StatusCode != 200 {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"message": "Invalid token"})
-		return
+		return "", errInvalidToken
 	}
-	defer resp.Body.Close()
 
 	// Get the user's ID
 	body := DiscordAuthBody{}
 	data, _ := io.ReadAll(resp.Body)
 	if err := json.Unmarshal(data, &body); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"err": err.Error()})
-		return
+		return "", err
 	}
 
-	ctx.Set(USER_ID_KEY, body.UserId)
-	ctx.Next()
+	return body.UserId, nil
 }
 
 type DiscordAuthBody struct {
 	UserId string `json:"id"`
 }
+
+// getTenant returns the tenant for the guild, making sure it's one of Tenants
+// and that the token's user is a member of it. An empty guild is the default
+// community, which everyone belongs to.
+func getTenant(authToken, guild string) (string, error) {
+	if guild == "" {
+		return models.DefaultTenant, nil
+	}
+
+	if _, ok := Tenants[guild]; !ok {
+		return "", errInvalidGuild
+	}
+
+	// Make a GET request to the Discord API to get the user's guilds
+	client := &http.Client{}
+	req, _ := http.NewRequest("GET", "https://discord.com/api/users/@me/guilds", nil)
+	req.Header.Add("Authorization", authToken)
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", errInvalidGuild
+	}
+	defer resp.Body.Close()
+
+	// Check the response
+	if resp.StatusCode != 200 {
+		return "", errInvalidGuild
+	}
+
+	guilds := []DiscordGuildBody{}
+	data, _ := io.ReadAll(resp.Body)
+	if err := json.Unmarshal(data, &guilds); err != nil {
+		return "", err
+	}
+
+	for _, g := range guilds {
+		if g.GuildId == guild {
+			return guild, nil
+		}
+	}
+
+	return "", errInvalidGuild
+}
+
+type DiscordGuildBody struct {
+	GuildId string `json:"id"`
+}
+
+// tenantConfig returns the config of the tenant db is scoped to, the default
+// community and sessions which aren't scoped get the defaults.
+func tenantConfig(db *gorm.DB) tenants.Tenant {
+	tenant, _ := models.GetTenant(db)
+	return Tenants[tenant]
+}
+
+// RequireAdmin only lets users in Admins through, it has to run after
+// DiscordAuth.
+func RequireAdmin(ctx *gin.Context) {
+	if !utils.Contains(Admins, ctx.GetString(USER_ID_KEY)) {
+		respondError(ctx, http.StatusForbidden, ErrNotAdmin)
+		return
+	}
+
+	ctx.Next()
+}
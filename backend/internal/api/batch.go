@@ -0,0 +1,183 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"keno/internal/db"
+	"keno/internal/engine"
+	"keno/internal/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// MaxBatchCards is the most cards which can be placed in a single batch.
+var MaxBatchCards = 20
+
+// Place a Batch of Keno Picks
+// @Summary Place several cards for the next Keno game at once
+// @Description Place up to `20` cards in one go, every card follows the same rules as placing a single card and they all start on the same game. Either every card is placed or none of them are, if any card is invalid the response lists the error for each one by its index in the request. Your own responsible gambling limits apply to the batch as a whole.
+// @Tags picks
+// @Accept json
+// @Produce json
+// @Param Idempotency-Key header string false "Unique key for the request, retries with the same key replay the first response instead of placing another card"
+// @Param picks body BatchPickRequest true "The cards to place"
+// @Success 200 {object} BatchPickResponse
+// @Failure 400 {object} BatchPickErrorResponse
+// @Failure 403 {object} APIError
+// @Failure 409 {object} APIError
+// @Failure 500 {object} APIError
+// @Router /api/v1/picks/batch [post]
+func PlaceBatchPicks(ctx *gin.Context) {
+
+	// Get the batch from the request
+	req := BatchPickRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		log.WithField("src", "api.PlaceBatchPicks").Error("Batch call made with invalid JSON body")
+		respondError(ctx, http.StatusBadRequest, bindError(ErrInvalidBatch, err))
+		return
+	}
+
+	if len(req.Cards) == 0 || len(req.Cards) > MaxBatchCards {
+		log.WithField("src", "api.PlaceBatchPicks").Error("Batch call made with an invalid number of cards")
+		respondError(ctx, http.StatusBadRequest, ErrInvalidBatch)
+		return
+	}
+
+	userId := ctx.GetString(USER_ID_KEY)
+
+	// Validate every card so all the problems can be reported at once
+	entryErrors := make([]BatchPickError, 0)
+	for i := range req.Cards {
+		req.Cards[i].generatePicks(userId)
+		if err := req.Cards[i].validate(); err != nil {
+			entryErrors = append(entryErrors, BatchPickError{Index: i, Error: toAPIError(err)})
+		}
+	}
+
+	if len(entryErrors) > 0 {
+		log.WithField("src", "api.PlaceBatchPicks").Error("Batch call made with invalid cards")
+		respondBatchError(ctx, entryErrors)
+		return
+	}
+
+	// Get the database from the context
+	db, ok := ctx.Get(db.DbKey)
+	if !ok {
+		log.WithField("src", "api.PlaceBatchPicks").Error("Database not found in context")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	// Get the game engine from the context
+	gameEngine, ok := ctx.Get(engine.EngineKey)
+	if !ok {
+		log.WithField("src", "api.PlaceBatchPicks").Error("Game Engine not found in context")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	// Place every card in one transaction on the next game open for betting.
+	// The liability checks see the cards placed earlier in the batch, so the
+	// batch as a whole can't push a game over its limit.
+	resp := BatchPickResponse{Cards: make([]PickResponse, 0, len(req.Cards))}
+	placed := make([]models.Card, 0, len(req.Cards))
+	err := gameEngine.(*engine.Engine).WithOpenGame(func(gameNum uint64) error {
+		startTime := gameEngine.(*engine.Engine).GetNextGame().UnixMilli()
+
+		return db.(*gorm.DB).Transaction(func(tx *gorm.DB) error {
+			// The user's own limits apply to the batch as a whole
+			cards := make([]models.Card, 0, len(req.Cards))
+			for _, entry := range req.Cards {
+				cards = append(cards, entry.toCard(gameNum, userId))
+			}
+
+			if err := checkLimits(tx, userId, cards); err != nil {
+				return err
+			}
+
+			for i, entry := range cards {
+				card, err := submitCard(tx, entry)
+
+				var apiErr APIError
+				if errors.As(err, &apiErr) {
+					entryErrors = append(entryErrors, BatchPickError{Index: i, Error: apiErr})
+					continue
+				}
+				if err != nil {
+					return err
+				}
+
+				cardResp := cardToPickResponse(*card)
+				cardResp.StartTime = startTime
+				resp.Cards = append(resp.Cards, cardResp)
+				placed = append(placed, *card)
+			}
+
+			if len(entryErrors) > 0 {
+				return ErrInvalidBatch
+			}
+
+			return nil
+		})
+	})
+	if errors.Is(err, ErrInvalidBatch) {
+		log.WithField("src", "api.PlaceBatchPicks").Error("Batch call would exceed the game liability")
+		respondBatchError(ctx, entryErrors)
+		return
+	}
+
+	var apiErr APIError
+	if errors.As(err, &apiErr) {
+		log.WithField("src", "api.PlaceBatchPicks").WithError(err).Error("Batch call blocked by a limit")
+		respondError(ctx, errorStatus(apiErr), apiErr)
+		return
+	}
+	if err != nil {
+		log.WithField("src", "api.PlaceBatchPicks").WithError(err).Error("Error submitting batch")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	for _, card := range placed {
+		notifyCardPlaced(gameEngine.(*engine.Engine), card)
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// respondBatchError aborts the request with the errors for each invalid card
+// in the batch. The details of every card's error are also collected into the
+// batch's own error, with their fields under the card's index.
+func respondBatchError(ctx *gin.Context, entryErrors []BatchPickError) {
+	apiErr := ErrInvalidBatch
+	for _, entry := range entryErrors {
+		apiErr = apiErr.WithDetails(entry.Error.WithFieldPrefix(fmt.Sprintf("cards[%d].", entry.Index)).Details...)
+	}
+	apiErr.RequestId = ctx.GetString(REQUEST_ID_KEY)
+
+	ctx.AbortWithStatusJSON(http.StatusBadRequest, BatchPickErrorResponse{APIError: apiErr, Entries: entryErrors})
+}
+
+type BatchPickRequest struct {
+	Cards []PickRequest `json:"cards"`
+}
+
+type BatchPickResponse struct {
+	Cards []PickResponse `json:"cards"`
+}
+
+// BatchPickError is the error for a single card in a batch, Index is its
+// position in the request.
+type BatchPickError struct {
+	Index int      `json:"index"`
+	Error APIError `json:"error"`
+}
+
+type BatchPickErrorResponse struct {
+	APIError
+	Entries []BatchPickError `json:"entries"`
+}
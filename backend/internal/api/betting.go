@@ -0,0 +1,74 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// BettingConfig is the config file the betting limits are loaded from, so they
+// can be changed without touching the code. Every field which is left out
+// keeps its default.
+type BettingConfig struct {
+	StakeMin         *uint64  `json:"stake_min,omitempty"`
+	StakeMax         *uint64  `json:"stake_max,omitempty"`
+	Stakes           []uint64 `json:"stakes,omitempty"`
+	MaxCardTotal     *uint64  `json:"max_card_total,omitempty"`
+	MaxGameLiability *uint64  `json:"max_game_liability,omitempty"`
+}
+
+// LoadBettingLimits reads the betting limits from the JSON config file at
+// path. A missing file keeps the defaults.
+func LoadBettingLimits(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		log.WithField("src", "api.LoadBettingLimits").Warn("No betting config found, using the defaults")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var config BettingConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return err
+	}
+
+	stakeMin, stakeMax, stakes := ValidStakeMin, ValidStakeMax, ValidStakes
+	if config.StakeMin != nil {
+		stakeMin = *config.StakeMin
+	}
+	if config.StakeMax != nil {
+		stakeMax = *config.StakeMax
+	}
+	if config.Stakes != nil {
+		stakes = config.Stakes
+	}
+
+	if stakeMin == 0 || stakeMin > stakeMax {
+		return errors.New("stake_min has to be at least 1 and no more than stake_max")
+	}
+	for _, stake := range stakes {
+		if stake < stakeMin || stake > stakeMax {
+			return errors.New("stakes have to be between stake_min and stake_max")
+		}
+	}
+	if config.MaxCardTotal != nil && *config.MaxCardTotal < stakeMin {
+		return errors.New("max_card_total has to be at least stake_min")
+	}
+	if config.MaxGameLiability != nil && *config.MaxGameLiability == 0 {
+		return errors.New("max_game_liability can't be 0")
+	}
+
+	ValidStakeMin, ValidStakeMax, ValidStakes = stakeMin, stakeMax, stakes
+	if config.MaxCardTotal != nil {
+		MaxCardTotal = *config.MaxCardTotal
+	}
+	if config.MaxGameLiability != nil {
+		MaxGameLiability = *config.MaxGameLiability
+	}
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"keno/internal/db"
 	"keno/internal/engine"
 	"keno/internal/models"
@@ -14,7 +15,7 @@ import (
 
 // Check Card
 // @Summary Check your card to see if you won
-// @Description Check your card to see if you won and claim your wins.
+// @Description Check your card to see if you won and claim your wins. Winnings are paid automatically as each game is settled, the response lists the result of every game on the card which has been settled so far.
 // @Tags cards
 // @param card_id path int true "Card ID"
 // @Produce json
@@ -27,53 +28,174 @@ func CheckCard(ctx *gin.Context) {
 	// Get Card Id from URL
 	cardIdStr := ctx.Param("card_id")
 	if cardIdStr == "" {
-		ctx.JSON(400, ErrInvalidCard)
+		respondError(ctx, 400, ErrInvalidCard)
 		return
 	}
 
 	// Convert to uint64
 	cardId, err := strconv.ParseUint(cardIdStr, 10, 64)
 	if err != nil {
-		ctx.JSON(400, ErrInvalidCard)
+		respondError(ctx, 400, ErrInvalidCard)
 		return
 	}
 
 	// Get the database from the context
 	db, ok := ctx.Get(db.DbKey)
 	if !ok {
-		ctx.JSON(500, ErrInternalError)
+		respondError(ctx, 500, ErrInternalError)
 		return
 	}
 
+	// Get the game engine from the context
+	gameEngine, ok := ctx.Get(engine.EngineKey)
+	if !ok {
+		respondError(ctx, 500, ErrInternalError)
+		return
+	}
+
+	resp, err := CheckCardWinnings(db.(*gorm.DB), gameEngine.(*engine.Engine), cardId)
+	switch {
+	case errors.Is(err, ErrInvalidCard), errors.Is(err, ErrUnfinishedGames):
+		respondError(ctx, 404, err)
+		return
+	case err != nil:
+		respondError(ctx, 500, ErrInternalError)
+		return
+	}
+
+	// Return the ammount
+	ctx.JSON(200, resp)
+}
+
+// CheckCardWinnings checks the card once all of its games have finished and
+// returns what it won on each of them. It's shared by the REST and gRPC APIs,
+// returning ErrInvalidCard or ErrUnfinishedGames if the card can't be checked.
+func CheckCardWinnings(db *gorm.DB, gameEngine *engine.Engine, cardId uint64) (*CheckCardResponse, error) {
 	// Get the card from the database
-	card, err := models.GetCard(db.(*gorm.DB), cardId)
+	card, err := models.GetCard(db, cardId)
+	if err != nil {
+		return nil, ErrInvalidCard
+	}
+
+	// Check if the game is finished
+	if card.LastGame > gameEngine.GetGameNumber() {
+		log.Infof("Card last game %d and engine game %d", card.LastGame, gameEngine.GetGameNumber())
+		return nil, ErrUnfinishedGames
+	}
+
+	// Check the card
+	amount := card.CheckCard(db)
+
+	// Get the settled results for each game
+	results, err := models.GetCardResults(db, card.ID)
+	if err != nil {
+		log.WithField("src", "api.CheckCardWinnings").WithError(err).Error("Error getting card results")
+		return nil, err
+	}
+
+	resp := CheckCardResponse{Amount: amount, Games: make([]CardGameResult, 0, len(results))}
+	for _, result := range results {
+		resp.Games = append(resp.Games, CardGameResult{
+			GameId:  result.GameID,
+			Matches: result.Matches,
+			Prize:   result.Prize,
+		})
+	}
+
+	return &resp, nil
+}
+
+type CheckCardResponse struct {
+	Amount uint64           `json:"amount"`
+	Games  []CardGameResult `json:"games"`
+}
+
+type CardGameResult struct {
+	GameId  uint64 `json:"game_id"`
+	Matches uint8  `json:"matches"`
+	Prize   uint64 `json:"prize"`
+}
+
+// Cancel Card
+// @Summary Cancel the games on your card which haven't started
+// @Description Cancel every game on your card which hasn't started drawing yet and refund the stake for them. Games which have already been drawn, or are being drawn, are kept on the card.
+// @Tags cards
+// @param card_id path int true "Card ID"
+// @Produce json
+// @Success 200 {object} CancelCardResponse
+// @Failure 400 {object} APIError
+// @Failure 404 {object} APIError
+// @Failure 409 {object} APIError
+// @Failure 500 {object} APIError
+// @Router /api/v1/cancel/{card_id} [post]
+func CancelCard(ctx *gin.Context) {
+	// Get Card Id from URL
+	cardId, err := strconv.ParseUint(ctx.Param("card_id"), 10, 64)
 	if err != nil {
-		ctx.JSON(404, ErrInvalidCard)
+		respondError(ctx, 400, ErrInvalidCard)
 		return
 	}
 
-	// Get the game engine from the context
-	gameEngine, ok := ctx.Get(engine.EngineKey)
+	// Get the database from the context
+	db, ok := ctx.Get(db.DbKey)
 	if !ok {
-		ctx.JSON(500, ErrInternalError)
+		respondError(ctx, 500, ErrInternalError)
 		return
 	}
 
-	// Check if the game is finished
-	if card.LastGame > gameEngine.(*engine.Engine).GetGameNumber() {
-		log.Infof("Card last game %d and engine game %d", card.LastGame, gameEngine.(*engine.Engine).GetGameNumber())
+	// Get the card from the database, only the owner can cancel it
+	card, err := models.GetCard(db.(*gorm.DB), cardId)
+	if err != nil || card.User != ctx.GetString(USER_ID_KEY) {
+		respondError(ctx, 404, ErrInvalidCard)
+		return
+	}
 
-		ctx.JSON(404, ErrUnfinishedGames)
+	// Syndicate cards belong to every member, so can't be cancelled by one
+	if card.SyndicateID != 0 {
+		respondError(ctx, 409, ErrSyndicateCard)
 		return
 	}
 
-	// Check the card
-	amount := card.CheckCard(db.(*gorm.DB))
+	// Tournament cards were paid for from the bankroll, so can't be refunded
+	if card.TournamentID != 0 {
+		respondError(ctx, 409, ErrTournamentCard)
+		return
+	}
 
-	// Return the ammount
-	ctx.JSON(200, CheckCardResponse{Amount: amount})
+	// Get the game engine from the context
+	gameEngine, ok := ctx.Get(engine.EngineKey)
+	if !ok {
+		respondError(ctx, 500, ErrInternalError)
+		return
+	}
+
+	// Cancel everything which is still open for betting
+	var cancellation *models.Cancellation
+	err = gameEngine.(*engine.Engine).WithOpenGame(func(gameNum uint64) error {
+		cancellation, err = models.CancelCard(db.(*gorm.DB), card, gameNum)
+		return err
+	})
+	if errors.Is(err, models.ErrNothingToCancel) {
+		respondError(ctx, 409, ErrNothingToCancel)
+		return
+	}
+	if err != nil {
+		log.WithField("src", "api.CancelCard").WithError(err).Error("Error cancelling card")
+		respondError(ctx, 500, ErrInternalError)
+		return
+	}
+
+	ctx.JSON(200, CancelCardResponse{
+		CardId:    card.ID,
+		StartGame: card.StartGame,
+		LastGame:  card.LastGame,
+		Refund:    cancellation.Refund,
+	})
 }
 
-type CheckCardResponse struct {
-	Amount uint64 `json:"amount"`
+type CancelCardResponse struct {
+	CardId    uint64 `json:"card_id"`
+	StartGame uint64 `json:"start_game_num"`
+	LastGame  uint64 `json:"last_game_num"`
+	Refund    uint64 `json:"refund"`
 }
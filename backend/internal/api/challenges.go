@@ -0,0 +1,499 @@
+package api
+
+import (
+	"errors"
+	"keno/internal/db"
+	"keno/internal/engine"
+	"keno/internal/models"
+	"keno/internal/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// Challenges can be made on any game up to MaxChallengeGamesAhead games
+	// after the next one open for betting
+	MaxChallengeGamesAhead uint64 = 100
+	ValidChallengeRules           = []string{models.ChallengeRuleMatches, models.ChallengeRulePrize}
+)
+
+// Challenge a User
+// @Summary Challenge another user to a head-to-head on a game
+// @Description Challenge another user to pick numbers for the same game, each of you putting up `stake`. You submit your selection now and they submit theirs when they accept, using the same number of picks. Whoever does better takes the pot of both stakes, and if you draw you each get your stake back.
+// @Description - With the `MATCHES` rule whoever matches more numbers wins.
+// @Description - With the `PRIZE` rule whoever's selection would win the bigger prize as a card wins.
+// @Description
+// @Description The challenge is on `game_num`, or the next game open for betting if it's left out. It has to be accepted before that game's draw starts, otherwise it expires and your stake is refunded. The selection and stake follow the same rules as placing picks, and both of your responsible gambling limits apply.
+// @Tags challenges
+// @Accept json
+// @Produce json
+// @Param Idempotency-Key header string false "Unique key for the request, retries with the same key replay the first response instead of placing another card"
+// @Param challenge body ChallengeRequest true "Who to challenge and your selection"
+// @Success 200 {object} ChallengeResponse
+// @Failure 400 {object} APIError
+// @Failure 403 {object} APIError
+// @Failure 409 {object} APIError
+// @Failure 500 {object} APIError
+// @Router /api/v1/challenges [post]
+func CreateChallenge(ctx *gin.Context) {
+	req := ChallengeRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		log.WithField("src", "api.CreateChallenge").Error("Challenge call made with invalid JSON body")
+		respondError(ctx, http.StatusBadRequest, bindError(ErrInvalidChallenge, err))
+		return
+	}
+
+	userId := ctx.GetString(USER_ID_KEY)
+	if req.Opponent == "" || req.Opponent == userId || !utils.Contains(ValidChallengeRules, req.Rule) {
+		log.WithField("src", "api.CreateChallenge").Error("Challenge call made with invalid values")
+		respondError(ctx, http.StatusBadRequest, ErrInvalidChallenge)
+		return
+	}
+
+	// The selection and stake are checked as a single game card
+	pickReq := PickRequest{
+		PicksPerGame: req.PicksPerGame,
+		Picks:        req.Picks,
+		PricePerGame: req.Stake,
+		NumGames:     1,
+		QuickPick:    req.QuickPick,
+	}
+	pickReq.generatePicks(userId)
+	if err := pickReq.validate(); err != nil {
+		log.WithField("src", "api.CreateChallenge").WithError(err).Error("Challenge call made with invalid picks")
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	// Get the database from the context
+	db, ok := ctx.Get(db.DbKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	// Get the game engine from the context
+	gameEngine, ok := ctx.Get(engine.EngineKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	var challenge *models.Challenge
+	err := gameEngine.(*engine.Engine).WithOpenGame(func(gameNum uint64) error {
+		if req.GameNum == 0 {
+			req.GameNum = gameNum
+		}
+
+		if req.GameNum < gameNum {
+			return ErrChallengeGameStarted
+		}
+		if req.GameNum > gameNum+MaxChallengeGamesAhead {
+			return ErrInvalidChallenge
+		}
+
+		return db.(*gorm.DB).Transaction(func(tx *gorm.DB) (err error) {
+			if err := checkStakes(tx, userId, []uint64{req.Stake}); err != nil {
+				return err
+			}
+
+			challenge, err = models.CreateChallenge(tx, models.Challenge{
+				GameID:          req.GameNum,
+				Stake:           req.Stake,
+				Rule:            req.Rule,
+				ChallengerPicks: pickReq.Picks,
+				Challenger:      userId,
+				Opponent:        req.Opponent,
+			})
+			return err
+		})
+	})
+
+	var apiErr APIError
+	if errors.As(err, &apiErr) {
+		log.WithField("src", "api.CreateChallenge").WithError(err).Error("Challenge call refused")
+		respondError(ctx, challengeErrorStatus(apiErr), apiErr)
+		return
+	}
+	if err != nil {
+		log.WithField("src", "api.CreateChallenge").WithError(err).Error("Error creating challenge")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	notifyChallenge(gameEngine.(*engine.Engine), *challenge)
+	ctx.JSON(http.StatusOK, challengeToResponse(*challenge))
+}
+
+// List Challenges
+// @Summary List your challenges
+// @Description Get every challenge you have made or been sent, newest first.
+// @Tags challenges
+// @Produce json
+// @Success 200 {array} ChallengeResponse
+// @Failure 500 {object} APIError
+// @Router /api/v1/challenges [get]
+func ListChallenges(ctx *gin.Context) {
+	// Get the database from the context
+	db, ok := ctx.Get(db.DbKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	challenges, err := models.GetChallenges(db.(*gorm.DB), ctx.GetString(USER_ID_KEY))
+	if err != nil {
+		log.WithField("src", "api.ListChallenges").WithError(err).Error("Error getting challenges")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	resp := make([]ChallengeResponse, 0, len(challenges))
+	for _, challenge := range challenges {
+		resp = append(resp, challengeToResponse(challenge))
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// Get a Challenge
+// @Summary Get one of your challenges
+// @Description Get a challenge you have made or been sent.
+// @Tags challenges
+// @param challenge_id path int true "Challenge ID"
+// @Produce json
+// @Success 200 {object} ChallengeResponse
+// @Failure 400 {object} APIError
+// @Failure 404 {object} APIError
+// @Failure 500 {object} APIError
+// @Router /api/v1/challenges/{challenge_id} [get]
+func GetChallenge(ctx *gin.Context) {
+	challenge, ok := getUserChallenge(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, challengeToResponse(*challenge))
+}
+
+// Accept a Challenge
+// @Summary Accept a challenge you have been sent
+// @Description Accept a pending challenge by submitting your own selection, with the same number of picks as the challenger's. Your stake is taken when you accept, and the challenge has to be accepted before its game's draw starts.
+// @Tags challenges
+// @Accept json
+// @Produce json
+// @param challenge_id path int true "Challenge ID"
+// @Param Idempotency-Key header string false "Unique key for the request, retries with the same key replay the first response instead of placing another card"
+// @Param selection body AcceptChallengeRequest true "Your selection"
+// @Success 200 {object} ChallengeResponse
+// @Failure 400 {object} APIError
+// @Failure 403 {object} APIError
+// @Failure 404 {object} APIError
+// @Failure 409 {object} APIError
+// @Failure 500 {object} APIError
+// @Router /api/v1/challenges/{challenge_id}/accept [post]
+func AcceptChallenge(ctx *gin.Context) {
+	challenge, ok := getUserChallenge(ctx)
+	if !ok {
+		return
+	}
+
+	userId := ctx.GetString(USER_ID_KEY)
+	if challenge.Opponent != userId {
+		respondError(ctx, http.StatusNotFound, ErrInvalidChallenge)
+		return
+	}
+
+	req := AcceptChallengeRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		log.WithField("src", "api.AcceptChallenge").Error("Accept call made with invalid JSON body")
+		respondError(ctx, http.StatusBadRequest, bindError(ErrInvalidChallenge, err))
+		return
+	}
+
+	// The selection has to be the same size as the challenger's
+	pickReq := PickRequest{
+		PicksPerGame: uint8(len(challenge.ChallengerPicks)),
+		Picks:        req.Picks,
+		PricePerGame: challenge.Stake,
+		NumGames:     1,
+		QuickPick:    req.QuickPick,
+	}
+	pickReq.generatePicks(userId)
+	if err := pickReq.validate(); err != nil {
+		log.WithField("src", "api.AcceptChallenge").WithError(err).Error("Accept call made with invalid picks")
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	// Get the database from the context
+	db, ok := ctx.Get(db.DbKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	// Get the game engine from the context
+	gameEngine, ok := ctx.Get(engine.EngineKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	err := gameEngine.(*engine.Engine).WithOpenGame(func(gameNum uint64) error {
+		if challenge.GameID < gameNum {
+			return ErrChallengeGameStarted
+		}
+
+		return db.(*gorm.DB).Transaction(func(tx *gorm.DB) error {
+			if err := checkStakes(tx, userId, []uint64{challenge.Stake}); err != nil {
+				return err
+			}
+
+			return models.AcceptChallenge(tx, challenge, pickReq.Picks)
+		})
+	})
+	if errors.Is(err, models.ErrChallengeNotPending) {
+		respondError(ctx, http.StatusConflict, ErrChallengeNotPending)
+		return
+	}
+
+	var apiErr APIError
+	if errors.As(err, &apiErr) {
+		log.WithField("src", "api.AcceptChallenge").WithError(err).Error("Accept call refused")
+		respondError(ctx, challengeErrorStatus(apiErr), apiErr)
+		return
+	}
+	if err != nil {
+		log.WithField("src", "api.AcceptChallenge").WithError(err).Error("Error accepting challenge")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	notifyChallenge(gameEngine.(*engine.Engine), *challenge)
+	ctx.JSON(http.StatusOK, challengeToResponse(*challenge))
+}
+
+// Cancel a Challenge
+// @Summary Withdraw or decline a challenge
+// @Description Cancel a challenge which hasn't been accepted yet, either one you made or one you've been sent. The challenger's stake is refunded.
+// @Tags challenges
+// @param challenge_id path int true "Challenge ID"
+// @Produce json
+// @Success 200 {object} ChallengeResponse
+// @Failure 400 {object} APIError
+// @Failure 404 {object} APIError
+// @Failure 409 {object} APIError
+// @Failure 500 {object} APIError
+// @Router /api/v1/challenges/{challenge_id} [delete]
+func CancelChallenge(ctx *gin.Context) {
+	challenge, ok := getUserChallenge(ctx)
+	if !ok {
+		return
+	}
+
+	// Get the database from the context
+	db, ok := ctx.Get(db.DbKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	// Get the game engine from the context
+	gameEngine, ok := ctx.Get(engine.EngineKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	err := models.CancelChallenge(db.(*gorm.DB), challenge)
+	if errors.Is(err, models.ErrChallengeNotPending) {
+		respondError(ctx, http.StatusConflict, ErrChallengeNotPending)
+		return
+	}
+	if err != nil {
+		log.WithField("src", "api.CancelChallenge").WithError(err).Error("Error cancelling challenge")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	notifyChallenge(gameEngine.(*engine.Engine), *challenge)
+	ctx.JSON(http.StatusOK, challengeToResponse(*challenge))
+}
+
+// getUserChallenge gets the challenge in the URL as long as the user is one of
+// the two users in it, otherwise it writes the error response. ok is false if
+// the response has been written.
+func getUserChallenge(ctx *gin.Context) (*models.Challenge, bool) {
+	challengeId, err := strconv.ParseUint(ctx.Param("challenge_id"), 10, 64)
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, ErrInvalidChallenge)
+		return nil, false
+	}
+
+	// Get the database from the context
+	db, ok := ctx.Get(db.DbKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return nil, false
+	}
+
+	userId := ctx.GetString(USER_ID_KEY)
+	challenge, err := models.GetChallenge(db.(*gorm.DB), challengeId)
+	if err != nil || (challenge.Challenger != userId && challenge.Opponent != userId) {
+		respondError(ctx, http.StatusNotFound, ErrInvalidChallenge)
+		return nil, false
+	}
+
+	return challenge, true
+}
+
+// challengeErrorStatus returns the HTTP status for an error found while making
+// or accepting a challenge.
+func challengeErrorStatus(err APIError) int {
+	if errors.Is(err, ErrChallengeGameStarted) {
+		return http.StatusConflict
+	}
+
+	return errorStatus(err)
+}
+
+// LockChallenges returns the betting closed hook which locks every accepted
+// challenge on the game and expires every one still pending, so none can be
+// accepted once the draw has started.
+func LockChallenges(db *gorm.DB, gameEngine *engine.Engine) func(gameNum uint64) {
+	return func(gameNum uint64) {
+		challenges, err := models.GetGameChallenges(db, gameNum, models.ChallengePending, models.ChallengeAccepted)
+		if err != nil {
+			log.WithField("src", "api.LockChallenges").WithError(err).Error("Error getting challenges")
+			return
+		}
+
+		if err := models.LockChallenges(db, gameNum); err != nil {
+			log.WithField("src", "api.LockChallenges").WithError(err).Error("Error locking challenges")
+			return
+		}
+
+		for _, challenge := range challenges {
+			if challenge.Status == models.ChallengePending {
+				challenge.Status = models.ChallengeExpired
+			} else {
+				challenge.Status = models.ChallengeLocked
+			}
+
+			notifyChallenge(gameEngine, challenge)
+		}
+	}
+}
+
+// ResolveChallenges returns a settlement hook which works out who won each
+// challenge on the game. Challenges which were never accepted are expired, in
+// case the game started before they could be locked. If the game was abandoned
+// nobody wins and the challenges are cancelled.
+func ResolveChallenges(db *gorm.DB, gameEngine *engine.Engine) func(game models.Game, results []models.CardResult) error {
+	return func(game models.Game, results []models.CardResult) error {
+		challenges, err := models.GetGameChallenges(db, game.ID,
+			models.ChallengePending, models.ChallengeAccepted, models.ChallengeLocked)
+		if err != nil {
+			log.WithField("src", "api.ResolveChallenges").WithError(err).Error("Error getting challenges")
+			return err
+		}
+
+		var failed error
+		for i := range challenges {
+			challenge := &challenges[i]
+			switch {
+			case !game.Finished():
+				err = models.VoidChallenge(db, challenge)
+			case challenge.Status == models.ChallengePending:
+				err = models.ExpireChallenge(db, challenge)
+			default:
+				err = models.ResolveChallenge(db, challenge, &game)
+			}
+			if err != nil {
+				log.WithFields(log.Fields{
+					"src":       "api.ResolveChallenges",
+					"challenge": challenge.ID,
+				}).WithError(err).Error("Error resolving challenge")
+				failed = errors.Join(failed, err)
+				continue
+			}
+
+			notifyChallenge(gameEngine, *challenge)
+		}
+
+		return failed
+	}
+}
+
+// notifyChallenge sends a CHL message to both users in the challenge.
+func notifyChallenge(gameEngine *engine.Engine, challenge models.Challenge) {
+	msg := models.GenerateMessage(models.ChallengeMsg{
+		ChallengeId: challenge.ID,
+		GameId:      challenge.GameID,
+		Status:      challenge.Status,
+		Winner:      challenge.Winner,
+		Pot:         challenge.Pot(),
+	})
+
+	gameEngine.NotifyUser(challenge.Tenant, challenge.Challenger, msg)
+	gameEngine.NotifyUser(challenge.Tenant, challenge.Opponent, msg)
+}
+
+type ChallengeRequest struct {
+	Opponent string `json:"opponent"`
+	Rule     string `json:"rule"`
+	Stake    uint64 `json:"stake"`
+
+	// GameNum is the game the challenge is on, the next game open for
+	// betting if it's left out
+	GameNum uint64 `json:"game_num,omitempty"`
+
+	PicksPerGame uint8   `json:"picks_per_game"`
+	Picks        []uint8 `json:"picks"`
+	QuickPick    bool    `json:"quick_pick,omitempty"`
+}
+
+type AcceptChallengeRequest struct {
+	Picks     []uint8 `json:"picks"`
+	QuickPick bool    `json:"quick_pick,omitempty"`
+}
+
+type ChallengeResponse struct {
+	ChallengeId       uint64 `json:"challenge_id"`
+	GameId            uint64 `json:"game_id"`
+	Stake             uint64 `json:"stake"`
+	Pot               uint64 `json:"pot"`
+	Rule              string `json:"rule"`
+	Status            string `json:"status"`
+	Challenger        string `json:"challenger"`
+	Opponent          string `json:"opponent"`
+	ChallengerPicks   []int  `json:"challenger_picks"`
+	OpponentPicks     []int  `json:"opponent_picks"`
+	ChallengerMatches uint8  `json:"challenger_matches"`
+	OpponentMatches   uint8  `json:"opponent_matches"`
+	Winner            string `json:"winner,omitempty"`
+}
+
+func challengeToResponse(challenge models.Challenge) ChallengeResponse {
+	return ChallengeResponse{
+		ChallengeId:       challenge.ID,
+		GameId:            challenge.GameID,
+		Stake:             challenge.Stake,
+		Pot:               challenge.Pot(),
+		Rule:              challenge.Rule,
+		Status:            challenge.Status,
+		Challenger:        challenge.Challenger,
+		Opponent:          challenge.Opponent,
+		ChallengerPicks:   utils.ToInts(challenge.ChallengerPicks),
+		OpponentPicks:     utils.ToInts(challenge.OpponentPicks),
+		ChallengerMatches: challenge.ChallengerMatches,
+		OpponentMatches:   challenge.OpponentMatches,
+		Winner:            challenge.Winner,
+	}
+}
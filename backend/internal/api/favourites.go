@@ -0,0 +1,326 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"keno/internal/db"
+	"keno/internal/models"
+	"keno/internal/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	MaxFavourites          int64 = 20
+	MaxFavouriteNameLength       = 32
+)
+
+// List Favourites
+// @Summary List your saved favourite selections
+// @Description Get every set of numbers you have saved as a favourite.
+// @Tags favourites
+// @Produce json
+// @Success 200 {array} FavouriteResponse
+// @Failure 500 {object} APIError
+// @Router /api/v1/favourites [get]
+func ListFavourites(ctx *gin.Context) {
+	// Get the database from the context
+	db, ok := ctx.Get(db.DbKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	favourites, err := models.GetFavourites(db.(*gorm.DB), ctx.GetString(USER_ID_KEY))
+	if err != nil {
+		log.WithField("src", "api.ListFavourites").WithError(err).Error("Error getting favourites")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	resp := make([]FavouriteResponse, 0, len(favourites))
+	for _, favourite := range favourites {
+		resp = append(resp, favouriteToResponse(favourite))
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// Save Favourite
+// @Summary Save a favourite selection
+// @Description Save a named set of numbers so you can place cards with it later. You can pick between `1` and `40` different numbers from `1` to `80`, and save up to `20` favourites.
+// @Tags favourites
+// @Accept json
+// @Produce json
+// @Param favourite body SaveFavouriteRequest true "The favourite to save"
+// @Success 200 {object} FavouriteResponse
+// @Failure 400 {object} APIError
+// @Failure 500 {object} APIError
+// @Router /api/v1/favourites [post]
+func SaveFavourite(ctx *gin.Context) {
+	// Get the favourite from the request
+	req := SaveFavouriteRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		log.WithField("src", "api.SaveFavourite").Error("Favourite call made with invalid JSON body")
+		respondError(ctx, http.StatusBadRequest, bindError(ErrInvalidFavourite, err))
+		return
+	}
+
+	if err := req.validate(); err != nil {
+		log.WithField("src", "api.SaveFavourite").Error("Favourite call made with invalid values")
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	// Get the database from the context
+	db, ok := ctx.Get(db.DbKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	userId := ctx.GetString(USER_ID_KEY)
+
+	// Count and save in one transaction, so saving several at once can't go
+	// over the limit
+	var favourite *models.Favourite
+	err := db.(*gorm.DB).Transaction(func(tx *gorm.DB) error {
+		count, err := models.CountFavourites(tx, userId)
+		if err != nil {
+			return err
+		}
+
+		if count >= MaxFavourites {
+			return ErrTooManyFavourites
+		}
+
+		favourite, err = models.SaveFavourite(tx, req.Name, req.Picks, userId)
+		return err
+	})
+	if errors.Is(err, ErrTooManyFavourites) {
+		respondError(ctx, http.StatusBadRequest, ErrTooManyFavourites)
+		return
+	}
+	if err != nil {
+		log.WithField("src", "api.SaveFavourite").WithError(err).Error("Error saving favourite")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, favouriteToResponse(*favourite))
+}
+
+// Delete Favourite
+// @Summary Delete a favourite selection
+// @Description Delete one of your saved favourites.
+// @Tags favourites
+// @param favourite_id path int true "Favourite ID"
+// @Success 204
+// @Failure 400 {object} APIError
+// @Failure 404 {object} APIError
+// @Failure 500 {object} APIError
+// @Router /api/v1/favourites/{favourite_id} [delete]
+func DeleteFavourite(ctx *gin.Context) {
+	favouriteId, err := strconv.ParseUint(ctx.Param("favourite_id"), 10, 64)
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, ErrInvalidFavourite)
+		return
+	}
+
+	// Get the database from the context
+	db, ok := ctx.Get(db.DbKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	err = models.DeleteFavourite(db.(*gorm.DB), favouriteId, ctx.GetString(USER_ID_KEY))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondError(ctx, http.StatusNotFound, ErrInvalidFavourite)
+		return
+	}
+	if err != nil {
+		log.WithField("src", "api.DeleteFavourite").WithError(err).Error("Error deleting favourite")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// Play Favourite
+// @Summary Place a card with a favourite selection
+// @Description Place a card on the next game open for betting using the numbers from one of your favourites. Every number in the favourite is played unless `picks_per_game` is smaller, in which case it's placed as a system entry. Favourites of a size which can't be played as a single card, like `12` numbers, can only be played as a system entry so `picks_per_game` has to be given for them. The card follows the same rules as placing picks.
+// @Tags favourites
+// @Accept json
+// @Produce json
+// @Param Idempotency-Key header string false "Unique key for the request, retries with the same key replay the first response instead of placing another card"
+// @param favourite_id path int true "Favourite ID"
+// @Param card body PlayFavouriteRequest true "The stake and number of games"
+// @Success 200 {object} PickResponse
+// @Failure 400 {object} APIError
+// @Failure 404 {object} APIError
+// @Failure 409 {object} APIError
+// @Failure 500 {object} APIError
+// @Router /api/v1/favourites/{favourite_id}/play [post]
+func PlayFavourite(ctx *gin.Context) {
+	favouriteId, err := strconv.ParseUint(ctx.Param("favourite_id"), 10, 64)
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, ErrInvalidFavourite)
+		return
+	}
+
+	req := PlayFavouriteRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		log.WithField("src", "api.PlayFavourite").Error("Play call made with invalid JSON body")
+		respondError(ctx, http.StatusBadRequest, bindError(ErrInvalidPicks, err))
+		return
+	}
+
+	// Get the database from the context
+	db, ok := ctx.Get(db.DbKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	// Get the favourite from the database, only the owner can play it
+	favourite, err := models.GetFavourite(db.(*gorm.DB), favouriteId)
+	if err != nil || favourite.User != ctx.GetString(USER_ID_KEY) {
+		respondError(ctx, http.StatusNotFound, ErrInvalidFavourite)
+		return
+	}
+
+	// Favourites too big to play as a single card can only be played as a
+	// system, so the spots to play have to be given
+	if req.PicksPerGame == 0 && !utils.Contains(ValidPicksPerGame, uint8(len(favourite.Selection))) {
+		log.WithField("src", "api.PlayFavourite").Error("Play call made without picks per game")
+		respondError(ctx, http.StatusBadRequest, ErrInvalidPicks.WithDetails(ErrorDetail{
+			Field:   "picks_per_game",
+			Code:    DetailOutOfRange,
+			Message: fmt.Sprintf("has to be set to play a favourite of %d numbers", len(favourite.Selection)),
+		}))
+		return
+	}
+
+	pickReq := PickRequest{
+		PicksPerGame: uint8(len(favourite.Selection)),
+		Picks:        favourite.Selection,
+		PricePerGame: req.PricePerGame,
+		NumGames:     req.NumGames,
+	}
+
+	if req.PicksPerGame > 0 && req.PicksPerGame != pickReq.PicksPerGame {
+		pickReq.SystemSize = pickReq.PicksPerGame
+		pickReq.PicksPerGame = req.PicksPerGame
+	}
+
+	placeCard(ctx, "api.PlayFavourite", pickReq)
+}
+
+// Re-bet Card
+// @Summary Place a card again
+// @Description Place a new card on the next game open for betting with the same selection, stake and number of games as one of your existing cards. The card follows the same rules as placing picks.
+// @Tags picks
+// @Produce json
+// @Param Idempotency-Key header string false "Unique key for the request, retries with the same key replay the first response instead of placing another card"
+// @param card_id path int true "Card ID"
+// @Success 200 {object} PickResponse
+// @Failure 400 {object} APIError
+// @Failure 404 {object} APIError
+// @Failure 409 {object} APIError
+// @Failure 500 {object} APIError
+// @Router /api/v1/rebet/{card_id} [post]
+func RebetCard(ctx *gin.Context) {
+	cardId, err := strconv.ParseUint(ctx.Param("card_id"), 10, 64)
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, ErrInvalidCard)
+		return
+	}
+
+	// Get the database from the context
+	db, ok := ctx.Get(db.DbKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	// Get the card from the database, only the owner can re-bet it
+	card, err := models.GetCard(db.(*gorm.DB), cardId)
+	if err != nil || card.User != ctx.GetString(USER_ID_KEY) {
+		respondError(ctx, http.StatusNotFound, ErrInvalidCard)
+		return
+	}
+
+	// Play as many games as the card was placed with, even if some were
+	// cancelled since
+	lastGame, err := models.GetOriginalLastGame(db.(*gorm.DB), card)
+	if err != nil {
+		log.WithField("src", "api.RebetCard").WithError(err).Error("Error getting card games")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	card.LastGame = lastGame
+	placeCard(ctx, "api.RebetCard", cardToPickRequest(*card))
+}
+
+type SaveFavouriteRequest struct {
+	Name  string  `json:"name"`
+	Picks []uint8 `json:"picks"`
+}
+
+// validate checks the favourite has a name and can be played, returning
+// ErrInvalidFavourite with the details of every problem.
+func (r SaveFavouriteRequest) validate() error {
+	details := selectionProblems(r.Picks)
+
+	if len(r.Name) == 0 || len(r.Name) > MaxFavouriteNameLength {
+		details = append(details, ErrorDetail{
+			Field:   "name",
+			Code:    DetailOutOfRange,
+			Message: fmt.Sprintf("has to be between 1 and %d characters", MaxFavouriteNameLength),
+		})
+	}
+
+	// The favourite has to be playable, either on its own or as a system
+	if !utils.Contains(ValidPicksPerGame, uint8(len(r.Picks))) &&
+		(len(r.Picks) <= 1 || len(r.Picks) > int(ValidSystemSizeMax)) {
+		details = append(details, ErrorDetail{
+			Field:   "picks",
+			Code:    DetailWrongCount,
+			Message: fmt.Sprintf("%d numbers can't be played", len(r.Picks)),
+		})
+	}
+
+	if len(details) > 0 {
+		return ErrInvalidFavourite.WithDetails(details...)
+	}
+
+	return nil
+}
+
+type PlayFavouriteRequest struct {
+	PicksPerGame uint8  `json:"picks_per_game,omitempty"`
+	PricePerGame uint64 `json:"price_per_game"`
+	NumGames     uint8  `json:"number_games"`
+}
+
+type FavouriteResponse struct {
+	FavouriteId uint64 `json:"favourite_id"`
+	Name        string `json:"name"`
+	Selection   []int  `json:"selection"`
+}
+
+func favouriteToResponse(favourite models.Favourite) FavouriteResponse {
+	return FavouriteResponse{
+		FavouriteId: favourite.ID,
+		Name:        favourite.Name,
+		Selection:   utils.ToInts(favourite.Selection),
+	}
+}
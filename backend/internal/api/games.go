@@ -0,0 +1,294 @@
+package api
+
+import (
+	"errors"
+	"keno/internal/db"
+	"keno/internal/engine"
+	"keno/internal/models"
+	"keno/internal/utils"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	DefaultGamesPerPage = 25
+	MaxGamesPerPage     = 100
+
+	// FinishedGameCache is the Cache-Control header for games which have
+	// finished drawing, as they never change again
+	FinishedGameCache = "public, max-age=31536000, immutable"
+)
+
+// List Games
+// @Summary List past games
+// @Description Get the games which have been drawn, newest first, a page at a time. Games can be narrowed down to a range of game numbers, and to those which started within a time range.
+// @Tags games
+// @param page query int false "Page, starting from 1"
+// @param per_page query int false "Games per page, up to 100"
+// @param from_game query int false "Lowest game number"
+// @param to_game query int false "Highest game number"
+// @param since query int false "Only games which started at or after this time, in unix milliseconds"
+// @param until query int false "Only games which started before this time, in unix milliseconds"
+// @Produce json
+// @Success 200 {object} GamesResponse
+// @Failure 400 {object} APIError
+// @Failure 500 {object} APIError
+// @Router /api/v1/games [get]
+func ListGames(ctx *gin.Context) {
+	req := GamesRequest{}
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		log.WithField("src", "api.ListGames").Error("Games call made with an invalid query")
+		respondError(ctx, http.StatusBadRequest, bindError(ErrInvalidGamesQuery, err))
+		return
+	}
+
+	// Get the database from the context
+	db, ok := ctx.Get(db.DbKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	// Get the game engine from the context
+	gameEngine, ok := ctx.Get(engine.EngineKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	resp, err := LookupGames(db.(*gorm.DB), gameEngine.(*engine.Engine), req)
+	if errors.Is(err, ErrInvalidGamesQuery) {
+		log.WithField("src", "api.ListGames").Error("Games call made with invalid values")
+		respondError(ctx, http.StatusBadRequest, ErrInvalidGamesQuery)
+		return
+	}
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	// New games keep being added, so the list can't be cached
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// LookupGames returns the page of games the request asks for, filling in the
+// default page and page size. It's shared by the REST and gRPC APIs, returning
+// ErrInvalidGamesQuery if the request doesn't make sense.
+func LookupGames(db *gorm.DB, gameEngine *engine.Engine, req GamesRequest) (*GamesResponse, error) {
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.PerPage == 0 {
+		req.PerPage = DefaultGamesPerPage
+	}
+
+	if req.Page < 0 || req.PerPage < 0 || req.PerPage > MaxGamesPerPage ||
+		(req.ToGame > 0 && req.ToGame < req.FromGame) || req.Since < 0 || req.Until < 0 ||
+		(req.Until > 0 && req.Until < req.Since) {
+		return nil, ErrInvalidGamesQuery
+	}
+
+	filter := models.GameFilter{FromGame: req.FromGame, ToGame: req.ToGame}
+	if req.Since > 0 {
+		filter.Since = time.UnixMilli(req.Since)
+	}
+	if req.Until > 0 {
+		filter.Until = time.UnixMilli(req.Until)
+	}
+
+	// Read what's being drawn before the games, see gameStatus
+	drawing := gameEngine.GetDrawingGame()
+
+	games, total, err := models.GetGames(db, filter, (req.Page-1)*req.PerPage, req.PerPage)
+	if err != nil {
+		log.WithField("src", "api.LookupGames").WithError(err).Error("Error getting games")
+		return nil, err
+	}
+
+	resp := GamesResponse{
+		Games:   make([]GameResponse, 0, len(games)),
+		Page:    req.Page,
+		PerPage: req.PerPage,
+		Total:   total,
+	}
+
+	for _, game := range games {
+		resp.Games = append(resp.Games, gameToResponse(game, drawing))
+	}
+
+	return &resp, nil
+}
+
+// Get a Game
+// @Summary Get a game
+// @Description Get a game's drawn numbers, in the order they were drawn, along with when it started and finished and whether it's still drawing. Games which have drawn all their numbers never change, so are sent with long lived caching headers.
+// @Tags games
+// @param game_id path int true "Game ID"
+// @Produce json
+// @Success 200 {object} GameResponse
+// @Failure 400 {object} APIError
+// @Failure 404 {object} APIError
+// @Failure 500 {object} APIError
+// @Router /api/v1/games/{game_id} [get]
+func GetGame(ctx *gin.Context) {
+	gameId, err := strconv.ParseUint(ctx.Param("game_id"), 10, 64)
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, ErrInvalidGame)
+		return
+	}
+
+	// Get the database from the context
+	db, ok := ctx.Get(db.DbKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	// Get the game engine from the context
+	gameEngine, ok := ctx.Get(engine.EngineKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	resp, err := LookupGame(db.(*gorm.DB), gameEngine.(*engine.Engine), gameId)
+	if errors.Is(err, ErrInvalidGame) {
+		respondError(ctx, http.StatusNotFound, ErrInvalidGame)
+		return
+	}
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	if resp.Status == models.GameComplete {
+		ctx.Header("Cache-Control", FinishedGameCache)
+	} else {
+		ctx.Header("Cache-Control", "no-cache")
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// LookupGame returns the game with its drawn numbers and status. It's shared
+// by the REST and gRPC APIs, returning ErrInvalidGame if there isn't one.
+func LookupGame(db *gorm.DB, gameEngine *engine.Engine, gameId uint64) (*GameResponse, error) {
+	// Read what's being drawn before the game, see gameStatus
+	drawing := gameEngine.GetDrawingGame()
+
+	game, err := models.GetGame(db, gameId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidGame
+	}
+	if err != nil {
+		log.WithField("src", "api.LookupGame").WithError(err).Error("Error getting game")
+		return nil, err
+	}
+
+	resp := gameToResponse(*game, drawing)
+	return &resp, nil
+}
+
+// Current Game
+// @Summary Get the current game
+// @Description Get the state of the game being drawn, or the last one drawn while betting is open on the next. This is the same state the stream sends when it connects, for clients which can't keep a websocket open. `server_time` is when the state was read, so clients can allow for their clocks being off.
+// @Tags games
+// @Produce json
+// @Success 200 {object} CurrentGameResponse
+// @Failure 500 {object} APIError
+// @Router /api/v1/games/current [get]
+func GetCurrentGame(ctx *gin.Context) {
+	// Get the game engine from the context
+	gameEngine, ok := ctx.Get(engine.EngineKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	curGame := gameEngine.(*engine.Engine).GetCurrentGame()
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, CurrentGameResponse{
+		GameId:       curGame.GameId,
+		Phase:        curGame.Phase,
+		StartTime:    curGame.CurrentGameStartTime,
+		EndTime:      curGame.CurrentGameEndTime,
+		NextGameTime: curGame.NextGameTime,
+		Picks:        curGame.Picks,
+		ServerTime:   curGame.ServerTime,
+	})
+}
+
+// gameStatus returns whether the game is still drawing, complete or was
+// abandoned part way through its draw. drawing is the game being drawn and has
+// to be read before the game, a game's last pick is stored before the engine
+// moves on so a game read afterwards can never look abandoned while it's still
+// being drawn.
+func gameStatus(game models.Game, drawing uint64) string {
+	if len(game.Picks) >= engine.NumberPicks {
+		return models.GameComplete
+	}
+
+	if game.ID == drawing {
+		return models.GameDrawing
+	}
+
+	return models.GameAbandoned
+}
+
+type GamesRequest struct {
+	Page     int    `form:"page" binding:"min=0"`
+	PerPage  int    `form:"per_page" binding:"min=0"`
+	FromGame uint64 `form:"from_game"`
+	ToGame   uint64 `form:"to_game"`
+	Since    int64  `form:"since" binding:"min=0"`
+	Until    int64  `form:"until" binding:"min=0"`
+}
+
+type GamesResponse struct {
+	Games   []GameResponse `json:"games"`
+	Page    int            `json:"page"`
+	PerPage int            `json:"per_page"`
+	Total   int64          `json:"total"`
+}
+
+type GameResponse struct {
+	GameId    uint64 `json:"game_id"`
+	Picks     []int  `json:"picks"`
+	StartTime int64  `json:"start_time,omitempty"`
+	EndTime   int64  `json:"end_time,omitempty"`
+	Status    string `json:"status"`
+}
+
+type CurrentGameResponse struct {
+	GameId       uint64 `json:"game_id"`
+	Phase        string `json:"phase"`
+	StartTime    int64  `json:"start_time"`
+	EndTime      int64  `json:"end_time"`
+	NextGameTime int64  `json:"next_game_time"`
+	Picks        []int  `json:"picks"`
+	ServerTime   int64  `json:"server_time"`
+}
+
+func gameToResponse(game models.Game, drawing uint64) GameResponse {
+	resp := GameResponse{
+		GameId: game.ID,
+		Picks:  utils.ToInts(game.Picks),
+		Status: gameStatus(game, drawing),
+	}
+
+	// Games from before the times were recorded don't have them
+	if !game.StartTime.IsZero() {
+		resp.StartTime = game.StartTime.UnixMilli()
+		resp.EndTime = game.EndTime.UnixMilli()
+	}
+
+	return resp
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"keno/internal/db"
+	"keno/internal/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// HistoryLimit is how many of the most recent history entries are returned.
+var HistoryLimit = 100
+
+// Get History
+// @Summary Get your recent history
+// @Description Get the most recent things which have happened to your cards and subscriptions, newest first. This includes cancellations and every card a subscription placed or skipped.
+// @Tags history
+// @Produce json
+// @Success 200 {array} HistoryResponse
+// @Failure 500 {object} APIError
+// @Router /api/v1/history [get]
+func GetHistory(ctx *gin.Context) {
+	// Get the database from the context
+	db, ok := ctx.Get(db.DbKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	history, err := models.GetHistory(db.(*gorm.DB), ctx.GetString(USER_ID_KEY), HistoryLimit)
+	if err != nil {
+		log.WithField("src", "api.GetHistory").WithError(err).Error("Error getting history")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	resp := make([]HistoryResponse, 0, len(history))
+	for _, entry := range history {
+		resp = append(resp, HistoryResponse{
+			Event:          entry.Event,
+			CardId:         entry.CardID,
+			SubscriptionId: entry.SubscriptionID,
+			GameId:         entry.GameID,
+			Amount:         entry.Amount,
+			Note:           entry.Note,
+			Time:           entry.CreatedAt.UnixMilli(),
+		})
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+type HistoryResponse struct {
+	Event          string `json:"event"`
+	CardId         uint64 `json:"card_id,omitempty"`
+	SubscriptionId uint64 `json:"subscription_id,omitempty"`
+	GameId         uint64 `json:"game_id,omitempty"`
+	Amount         uint64 `json:"amount,omitempty"`
+	Note           string `json:"note,omitempty"`
+	Time           int64  `json:"time"`
+}
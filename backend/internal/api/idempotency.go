@@ -0,0 +1,137 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"keno/internal/db"
+	"keno/internal/models"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const IDEMPOTENCY_KEY_HEADER = "Idempotency-Key"
+
+var (
+	// IdempotencyRetention is how long a response is kept for replaying
+	IdempotencyRetention = 24 * time.Hour
+
+	// IdempotencyTimeout is how long a request can be in progress before its
+	// key can be used again, in case the server stopped part way through it
+	IdempotencyTimeout = time.Minute
+
+	MaxIdempotencyKeyLength = 255
+)
+
+// Idempotent lets clients safely retry requests which place cards. When the
+// request has an Idempotency-Key header the first response for that user and
+// key is stored, and repeating the request replays it instead of handling it
+// again. Reusing a key for a different request, or while the first one is
+// still being handled, is a conflict. Requests without the header are handled
+// as normal.
+func Idempotent(ctx *gin.Context) {
+	key := ctx.GetHeader(IDEMPOTENCY_KEY_HEADER)
+	if key == "" {
+		ctx.Next()
+		return
+	}
+
+	if len(key) > MaxIdempotencyKeyLength {
+		respondError(ctx, http.StatusBadRequest, ErrInvalidIdempotencyKey)
+		return
+	}
+
+	// Get the database from the context
+	db, ok := ctx.Get(db.DbKey)
+	if !ok {
+		log.WithField("src", "api.Idempotent").Error("Database not found in context")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	// Hash the request so reusing the key for something else can be caught,
+	// then put the body back for the handler
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, ErrInvalidRequest)
+		return
+	}
+	ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	hash := sha256.New()
+	hash.Write([]byte(ctx.Request.Method + " " + ctx.Request.URL.Path + "\n"))
+	hash.Write(body)
+	requestHash := hex.EncodeToString(hash.Sum(nil))
+
+	userId := ctx.GetString(USER_ID_KEY)
+	now := time.Now()
+	record, err := models.ReserveIdempotencyKey(db.(*gorm.DB), userId, key, requestHash,
+		now.Add(-IdempotencyRetention), now.Add(-IdempotencyTimeout))
+	if errors.Is(err, models.ErrIdempotencyKeyUsed) {
+		switch {
+		case record.RequestHash != requestHash:
+			respondError(ctx, http.StatusConflict, ErrIdempotencyKeyReused)
+		case record.Status == 0:
+			respondError(ctx, http.StatusConflict, ErrIdempotencyKeyInProgress)
+		default:
+			ctx.Header("Idempotent-Replayed", "true")
+			ctx.Data(record.Status, "application/json; charset=utf-8", record.Body)
+			ctx.Abort()
+		}
+		return
+	}
+	if err != nil {
+		log.WithField("src", "api.Idempotent").WithError(err).Error("Error reserving idempotency key")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	// Handle the request, keeping a copy of the response. If the handler
+	// panics the key is released on the way out, so it isn't left in progress.
+	writer := &recordingWriter{ResponseWriter: ctx.Writer}
+	ctx.Writer = writer
+	handled := false
+	defer func() {
+		if handled {
+			return
+		}
+
+		if err := models.ReleaseIdempotencyKey(db.(*gorm.DB), record.ID); err != nil {
+			log.WithField("src", "api.Idempotent").WithError(err).Error("Error releasing idempotency key")
+		}
+	}()
+
+	ctx.Next()
+
+	// Server errors may not have done anything, so let the client try again
+	if writer.Status() < http.StatusInternalServerError {
+		err = models.CompleteIdempotencyKey(db.(*gorm.DB), record.ID, writer.Status(), writer.body.Bytes())
+		if err != nil {
+			log.WithField("src", "api.Idempotent").WithError(err).Error("Error storing idempotent response")
+		}
+		handled = err == nil
+	}
+}
+
+// recordingWriter keeps a copy of everything written to the response
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
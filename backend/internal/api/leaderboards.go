@@ -0,0 +1,101 @@
+package api
+
+import (
+	"keno/internal/db"
+	"keno/internal/models"
+	"keno/internal/utils"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Get a Leaderboard
+// @Summary Get one of the leaderboards
+// @Description Get the top players on a leaderboard for a period. The leaderboards are worked out from every settled card, other than syndicate cards, and are updated after every game is settled.
+// @Description - `BIGGEST_WIN` is the biggest prize won on a single game.
+// @Description - `BEST_RETURN` is the percentage of what was staked that was won back, only players who have staked at least `100` in the period are ranked.
+// @Description - `TEN_SPOT_HITS` is how many times all ten numbers of a 10-spot selection were drawn.
+// @Description - `NET_PROFIT` is how much more was won than staked, which can be negative.
+// @Description
+// @Description The period is one of `DAILY`, `WEEKLY` or `MONTHLY`, which start at midnight UTC on the current day, Monday or the 1st, or `ALL_TIME` which is the default.
+// @Tags leaderboards
+// @param board path string true "Leaderboard" Enums(BIGGEST_WIN, BEST_RETURN, TEN_SPOT_HITS, NET_PROFIT)
+// @param period query string false "Period" Enums(DAILY, WEEKLY, MONTHLY, ALL_TIME)
+// @Produce json
+// @Success 200 {object} LeaderboardResponse
+// @Failure 400 {object} APIError
+// @Failure 500 {object} APIError
+// @Router /api/v1/leaderboards/{board} [get]
+func GetLeaderboard(ctx *gin.Context) {
+	board := strings.ToUpper(ctx.Param("board"))
+	period := strings.ToUpper(ctx.DefaultQuery("period", models.PeriodAllTime))
+	if !utils.Contains(models.Boards, board) || !utils.Contains(models.Periods, period) {
+		respondError(ctx, http.StatusBadRequest, ErrInvalidLeaderboard)
+		return
+	}
+
+	// Get the database from the context
+	db, ok := ctx.Get(db.DbKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	entries, err := models.GetLeaderboard(db.(*gorm.DB), board, period)
+	if err != nil {
+		log.WithField("src", "api.GetLeaderboard").WithError(err).Error("Error getting leaderboard")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	resp := LeaderboardResponse{
+		Board:   board,
+		Period:  period,
+		Entries: make([]LeaderboardEntryResponse, 0, len(entries)),
+	}
+
+	for _, entry := range entries {
+		resp.UpdatedAt = entry.UpdatedAt.UnixMilli()
+		resp.Entries = append(resp.Entries, LeaderboardEntryResponse{
+			Rank:  entry.Rank,
+			User:  entry.User,
+			Value: entry.Value,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// RefreshLeaderboards returns a settlement hook which adds each game's results
+// to the leaderboards once it has been settled.
+func RefreshLeaderboards(db *gorm.DB) func(game models.Game, results []models.CardResult) error {
+	return func(game models.Game, results []models.CardResult) error {
+		err := models.AddToLeaderboards(db, game.ID, results, time.Now())
+		if err != nil {
+			log.WithFields(log.Fields{
+				"src":  "api.RefreshLeaderboards",
+				"game": game.ID,
+			}).WithError(err).Error("Error refreshing leaderboards")
+		}
+
+		return err
+	}
+}
+
+type LeaderboardResponse struct {
+	Board     string                     `json:"board"`
+	Period    string                     `json:"period"`
+	UpdatedAt int64                      `json:"updated_at,omitempty"`
+	Entries   []LeaderboardEntryResponse `json:"entries"`
+}
+
+type LeaderboardEntryResponse struct {
+	Rank  int    `json:"rank"`
+	User  string `json:"user"`
+	Value int64  `json:"value"`
+}
@@ -0,0 +1,438 @@
+package api
+
+import (
+	"errors"
+	"keno/internal/db"
+	"keno/internal/models"
+	"keno/internal/utils"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// Raising or removing a limit only takes effect after this delay
+	LimitIncreaseDelay = 24 * time.Hour
+
+	MaxCoolOffHours      uint64 = 30 * 24
+	MinSelfExclusionDays uint64 = 30
+
+	// Self exclusions of zero days last until this time
+	PermanentExclusion = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+	// Errors returned when a card is blocked by the user's own limits
+	limitErrors = []APIError{
+		ErrSelfExcluded,
+		ErrCoolingOff,
+		ErrStakeLimit,
+		ErrGuildStakeLimit,
+		ErrDailyLossLimit,
+		ErrWeeklyLossLimit,
+		ErrMonthlyLossLimit,
+	}
+)
+
+// Get Limits
+// @Summary Get your responsible gambling limits
+// @Description Get the limits you have set on yourself, how much of each loss limit you have used across every guild, and any limit changes waiting to take effect.
+// @Tags limits
+// @Produce json
+// @Success 200 {object} LimitsResponse
+// @Failure 500 {object} APIError
+// @Router /api/v1/limits [get]
+func GetLimits(ctx *gin.Context) {
+	// Get the database from the context
+	db, ok := ctx.Get(db.DbKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	resp, err := limitsResponse(db.(*gorm.DB), ctx.GetString(USER_ID_KEY))
+	if err != nil {
+		log.WithField("src", "api.GetLimits").WithError(err).Error("Error getting limits")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// Set Limits
+// @Summary Set your responsible gambling limits
+// @Description Set how much you can lose in a rolling day, week or 30 days, and the most a single card can cost. Leave a limit out to keep it as it is, or set it to `0` to remove it.
+// @Description
+// @Description Lowering a limit takes effect straight away. Raising or removing one only takes effect after 24 hours.
+// @Tags limits
+// @Accept json
+// @Produce json
+// @Param limits body SetLimitsRequest true "The limits to change"
+// @Success 200 {object} LimitsResponse
+// @Failure 400 {object} APIError
+// @Failure 500 {object} APIError
+// @Router /api/v1/limits [put]
+func SetLimits(ctx *gin.Context) {
+	req := SetLimitsRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, bindError(ErrInvalidLimits, err))
+		return
+	}
+
+	// Get the database from the context
+	db, ok := ctx.Get(db.DbKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	userId := ctx.GetString(USER_ID_KEY)
+
+	limits, err := models.GetLimits(db.(*gorm.DB), userId)
+	if err != nil {
+		log.WithField("src", "api.SetLimits").WithError(err).Error("Error getting limits")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	now := time.Now()
+	for _, change := range []struct {
+		amount *uint64
+		limit  *models.Limit
+	}{
+		{req.DailyLoss, &limits.DailyLoss},
+		{req.WeeklyLoss, &limits.WeeklyLoss},
+		{req.MonthlyLoss, &limits.MonthlyLoss},
+		{req.MaxStake, &limits.MaxStake},
+	} {
+		if change.amount != nil {
+			change.limit.Set(*change.amount, now, LimitIncreaseDelay)
+		}
+	}
+
+	if err := models.SaveLimits(db.(*gorm.DB), limits); err != nil {
+		log.WithField("src", "api.SetLimits").WithError(err).Error("Error saving limits")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	resp, err := limitsResponse(db.(*gorm.DB), userId)
+	if err != nil {
+		log.WithField("src", "api.SetLimits").WithError(err).Error("Error getting limits")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// Cool Off
+// @Summary Take a break from placing cards
+// @Description Stop yourself from placing any cards for between `1` and `720` hours. A cool off can be extended but not shortened, and subscriptions skip their games while it lasts.
+// @Tags limits
+// @Accept json
+// @Produce json
+// @Param cool_off body CoolOffRequest true "How long to cool off for"
+// @Success 200 {object} LimitsResponse
+// @Failure 400 {object} APIError
+// @Failure 500 {object} APIError
+// @Router /api/v1/limits/cool-off [post]
+func CoolOff(ctx *gin.Context) {
+	req := CoolOffRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil || req.Hours == 0 || req.Hours > MaxCoolOffHours {
+		respondError(ctx, http.StatusBadRequest, bindError(ErrInvalidLimits, err))
+		return
+	}
+
+	until := time.Now().Add(time.Duration(req.Hours) * time.Hour)
+	updateLimits(ctx, "api.CoolOff", func(limits *models.Limits) {
+		if until.After(limits.CoolOffUntil) {
+			limits.CoolOffUntil = until
+		}
+	})
+}
+
+// Self Exclude
+// @Summary Exclude yourself from playing
+// @Description Stop yourself from placing any cards for at least `30` days, or for good if `days` is `0`. A self exclusion can be extended but not shortened, and every active subscription is cancelled.
+// @Tags limits
+// @Accept json
+// @Produce json
+// @Param exclusion body SelfExcludeRequest true "How long to exclude yourself for"
+// @Success 200 {object} LimitsResponse
+// @Failure 400 {object} APIError
+// @Failure 500 {object} APIError
+// @Router /api/v1/limits/self-exclude [post]
+func SelfExclude(ctx *gin.Context) {
+	req := SelfExcludeRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil || (req.Days != 0 && req.Days < MinSelfExclusionDays) {
+		respondError(ctx, http.StatusBadRequest, bindError(ErrInvalidLimits, err))
+		return
+	}
+
+	until := PermanentExclusion
+	if req.Days != 0 && req.Days < uint64(time.Until(PermanentExclusion)/(24*time.Hour)) {
+		until = time.Now().AddDate(0, 0, int(req.Days))
+	}
+
+	updateLimits(ctx, "api.SelfExclude", func(limits *models.Limits) {
+		if until.After(limits.ExcludedUntil) {
+			limits.ExcludedUntil = until
+		}
+	})
+}
+
+// updateLimits applies change to the user's limits and saves them, cancelling
+// any subscriptions which can no longer place cards, then writes the response.
+func updateLimits(ctx *gin.Context, src string, change func(limits *models.Limits)) {
+	// Get the database from the context
+	db, ok := ctx.Get(db.DbKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	userId := ctx.GetString(USER_ID_KEY)
+
+	err := db.(*gorm.DB).Transaction(func(tx *gorm.DB) error {
+		limits, err := models.GetLimits(tx, userId)
+		if err != nil {
+			return err
+		}
+
+		change(limits)
+		if err := models.SaveLimits(tx, limits); err != nil {
+			return err
+		}
+
+		if !time.Now().Before(limits.ExcludedUntil) {
+			return nil
+		}
+
+		subscriptions, err := models.GetSubscriptions(tx, userId)
+		if err != nil {
+			return err
+		}
+
+		for i := range subscriptions {
+			if subscriptions[i].Status != models.SubscriptionActive {
+				continue
+			}
+
+			err := models.StopSubscription(tx, &subscriptions[i], models.SubscriptionCancelled)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		log.WithField("src", src).WithError(err).Error("Error updating limits")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	resp, err := limitsResponse(db.(*gorm.DB), userId)
+	if err != nil {
+		log.WithField("src", src).WithError(err).Error("Error getting limits")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// lossPeriods are the rolling windows each loss limit applies over.
+var lossPeriods = []struct {
+	period time.Duration
+	limit  func(limits *models.Limits) models.Limit
+	err    APIError
+}{
+	{24 * time.Hour, func(l *models.Limits) models.Limit { return l.DailyLoss }, ErrDailyLossLimit},
+	{7 * 24 * time.Hour, func(l *models.Limits) models.Limit { return l.WeeklyLoss }, ErrWeeklyLossLimit},
+	{30 * 24 * time.Hour, func(l *models.Limits) models.Limit { return l.MonthlyLoss }, ErrMonthlyLossLimit},
+}
+
+// checkLimits makes sure the user's own limits allow them to place the cards,
+// returning the APIError for the first limit which would be broken.
+func checkLimits(db *gorm.DB, user string, cards []models.Card) error {
+	stakes := make([]uint64, 0, len(cards))
+	for _, card := range cards {
+		cost, ok := card.TotalCost()
+		if !ok {
+			return ErrStakeLimit
+		}
+
+		stakes = append(stakes, cost)
+	}
+
+	return checkStakes(db, user, stakes)
+}
+
+// checkStakes makes sure the user's own limits, and their guild's stake cap,
+// allow them to make bets costing each of the stakes, returning the APIError
+// for the first limit which would be broken. Limits belong to the user rather
+// than the guild, so their losses in every guild count towards them.
+func checkStakes(db *gorm.DB, user string, stakes []uint64) error {
+	limits, err := checkExclusion(db, user)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	total := uint64(0)
+	maxStake := limits.MaxStake.Current(now)
+	guildStake := tenantConfig(db).MaxStake
+	for _, stake := range stakes {
+		if maxStake > 0 && stake > maxStake {
+			return ErrStakeLimit
+		}
+
+		if guildStake > 0 && stake > guildStake {
+			return ErrGuildStakeLimit
+		}
+
+		var ok bool
+		total, ok = utils.AddUint64(total, stake)
+		if !ok {
+			return ErrStakeLimit
+		}
+	}
+
+	for _, loss := range lossPeriods {
+		limit := loss.limit(limits).Current(now)
+		if limit == 0 {
+			continue
+		}
+
+		lost, err := models.GetNetLoss(models.AllTenants(db), user, now.Add(-loss.period))
+		if err != nil {
+			return err
+		}
+
+		if lost, ok := utils.AddUint64(lost, total); !ok || lost > limit {
+			return loss.err
+		}
+	}
+
+	return nil
+}
+
+// checkExclusion makes sure the user isn't excluded or taking a break from
+// playing, and returns their limits.
+func checkExclusion(db *gorm.DB, user string) (*models.Limits, error) {
+	limits, err := models.GetLimits(db, user)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if now.Before(limits.ExcludedUntil) {
+		return nil, ErrSelfExcluded
+	}
+
+	if now.Before(limits.CoolOffUntil) {
+		return nil, ErrCoolingOff
+	}
+
+	return limits, nil
+}
+
+// errorStatus returns the HTTP status for an error found while placing a card,
+// cards blocked by the user's own limits are forbidden rather than invalid.
+func errorStatus(err APIError) int {
+	if IsLimitError(err) {
+		return http.StatusForbidden
+	}
+
+	return http.StatusBadRequest
+}
+
+// IsLimitError returns whether the error is a card being blocked by one of the
+// user's or guild's limits, rather than something wrong with the request.
+func IsLimitError(err APIError) bool {
+	for _, limitErr := range limitErrors {
+		if errors.Is(err, limitErr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type SetLimitsRequest struct {
+	DailyLoss   *uint64 `json:"daily_loss,omitempty"`
+	WeeklyLoss  *uint64 `json:"weekly_loss,omitempty"`
+	MonthlyLoss *uint64 `json:"monthly_loss,omitempty"`
+	MaxStake    *uint64 `json:"max_stake,omitempty"`
+}
+
+type CoolOffRequest struct {
+	Hours uint64 `json:"hours"`
+}
+
+type SelfExcludeRequest struct {
+	Days uint64 `json:"days"`
+}
+
+type LimitResponse struct {
+	Amount    uint64  `json:"amount"`
+	Pending   *uint64 `json:"pending,omitempty"`
+	PendingAt int64   `json:"pending_at,omitempty"`
+	Used      uint64  `json:"used,omitempty"`
+}
+
+type LimitsResponse struct {
+	DailyLoss     LimitResponse `json:"daily_loss"`
+	WeeklyLoss    LimitResponse `json:"weekly_loss"`
+	MonthlyLoss   LimitResponse `json:"monthly_loss"`
+	MaxStake      LimitResponse `json:"max_stake"`
+	CoolOffUntil  int64         `json:"cool_off_until,omitempty"`
+	ExcludedUntil int64         `json:"excluded_until,omitempty"`
+}
+
+func limitsResponse(db *gorm.DB, user string) (*LimitsResponse, error) {
+	limits, err := models.GetLimits(db, user)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	toResponse := func(limit models.Limit) LimitResponse {
+		resp := LimitResponse{Amount: limit.Current(now)}
+		if now.Before(limit.PendingAt) {
+			resp.Pending = &limit.Pending
+			resp.PendingAt = limit.PendingAt.UnixMilli()
+		}
+		return resp
+	}
+
+	resp := &LimitsResponse{
+		DailyLoss:   toResponse(limits.DailyLoss),
+		WeeklyLoss:  toResponse(limits.WeeklyLoss),
+		MonthlyLoss: toResponse(limits.MonthlyLoss),
+		MaxStake:    toResponse(limits.MaxStake),
+	}
+
+	for i, used := range []*LimitResponse{&resp.DailyLoss, &resp.WeeklyLoss, &resp.MonthlyLoss} {
+		used.Used, err = models.GetNetLoss(models.AllTenants(db), user, now.Add(-lossPeriods[i].period))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if now.Before(limits.CoolOffUntil) {
+		resp.CoolOffUntil = limits.CoolOffUntil.UnixMilli()
+	}
+
+	if now.Before(limits.ExcludedUntil) {
+		resp.ExcludedUntil = limits.ExcludedUntil.UnixMilli()
+	}
+
+	return resp, nil
+}
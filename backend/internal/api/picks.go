@@ -1,11 +1,14 @@
 package api
 
 import (
+	"errors"
+	"fmt"
 	"keno/internal/db"
 	"keno/internal/engine"
 	"keno/internal/models"
 	"keno/internal/utils"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -16,15 +19,25 @@ import (
 // Place your Keno Picks
 // @Summary Place your picks for the next Keno game
 // @Description Give us your numbers so you can enjoy the number of games you specify. There are some rules:
-// @Description - You can only pick numbers between `1` and `80`.
+// @Description - You can only pick numbers between `1` and `80`, and each number only once.
 // @Description - You can only pick `1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 15, 20, 40` numbers per game.
 // @Description - You can only play `1, 2, 3, 4, 5, 10, 20, 50, 100` number of games.
+// @Description - By default you can only stake `1, 2, 3, 5, 10, 20, 25, 50, 100` per game, and no more than `2000` for the whole card, the server's betting config can change these.
+// @Description - Set `quick_pick` and leave out `picks` to have the server pick your numbers for you, the response includes the numbers it picked.
+// @Description - For a system entry set `system_size` to how many numbers you're picking, up to `20`. Every `picks_per_game` sized combination of them is played, up to `1000` combinations, and the price per game is charged for each one.
+// @Description - Each game has a cap on how much it could pay out across every card, once it's reached the game won't take any more bets.
+// @Description - Cards which would break your own responsible gambling limits are refused with a `403`.
+// @Description
+// @Description Betting on a game closes as soon as its draw starts, so a card placed while a game is being drawn starts on the following game. The response says which game the card starts on and when that game is due to start.
 // @Tags picks
 // @Accept json
 // @Produce json
+// @Param Idempotency-Key header string false "Unique key for the request, retries with the same key replay the first response instead of placing another card"
 // @Param picks body PickRequest true "Your picks for the next selected games"
 // @Success 200 {object} PickResponse
 // @Failure 400 {object} APIError
+// @Failure 403 {object} APIError
+// @Failure 409 {object} APIError
 // @Failure 500 {object} APIError
 // @Router /api/v1/picks [post]
 func PlacePicks(ctx *gin.Context) {
@@ -33,52 +46,85 @@ func PlacePicks(ctx *gin.Context) {
 	req := PickRequest{}
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		log.WithField("src", "api.PlacePicks").Error("Picks call made with invalid JSON body")
-		ctx.JSON(http.StatusBadRequest, ErrInvalidPicks)
+		respondError(ctx, http.StatusBadRequest, bindError(ErrInvalidPicks, err))
 		return
 	}
 
-	// Validate the picks
-	if !req.isValid() {
-		log.WithField("src", "api.PlacePicks").Error("Picks call made with invalid values")
-		ctx.JSON(http.StatusBadRequest, ErrInvalidPicks)
-		return
-	}
+	placeCard(ctx, "api.PlacePicks", req)
+}
 
+// placeCard places the card for the user through PlaceCard, then writes the
+// response. Every endpoint which places a single card goes through here.
+func placeCard(ctx *gin.Context, src string, req PickRequest) {
 	// Get the database from the context
 	db, ok := ctx.Get(db.DbKey)
 	if !ok {
-		log.WithField("src", "api.PlacePicks").Error("Database not found in context")
-		ctx.JSON(http.StatusInternalServerError, ErrInternalError)
+		log.WithField("src", src).Error("Database not found in context")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
 		return
 	}
 
-	userId, _ := ctx.Get("user")
-
 	// Get the game engine from the context
 	gameEngine, ok := ctx.Get(engine.EngineKey)
 	if !ok {
-		log.WithField("src", "api.PlacePicks").Error("Game Engine not found in context")
-		ctx.JSON(http.StatusInternalServerError, ErrInternalError)
+		log.WithField("src", src).Error("Game Engine not found in context")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
 		return
 	}
 
-	// Place the picks
-	card, err := models.SubmitCard(
-		db.(*gorm.DB),
-		req.Picks,
-		gameEngine.(*engine.Engine).GetGameNumber(),
-		req.NumGames,
-		req.PricePerGame,
-		userId.(string),
-	)
+	resp, err := PlaceCard(db.(*gorm.DB), gameEngine.(*engine.Engine), ctx.GetString(USER_ID_KEY), req)
+
+	var apiErr APIError
+	if errors.As(err, &apiErr) {
+		log.WithField("src", src).WithError(err).Error("Picks call made with invalid values or blocked by a limit")
+		respondError(ctx, errorStatus(apiErr), apiErr)
+		return
+	}
 	if err != nil {
-		log.WithField("src", "api.PlacePicks").Error("Error submitting picks")
-		ctx.JSON(http.StatusInternalServerError, ErrInternalError)
+		log.WithField("src", src).Error("Error submitting picks")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
 		return
 	}
 
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// PlaceCard validates the request and places it for the user on the next game
+// open for betting. It's shared by the REST and gRPC APIs so they follow the
+// same rules, a broken rule or limit is returned as its APIError.
+func PlaceCard(db *gorm.DB, gameEngine *engine.Engine, userId string, req PickRequest) (*PickResponse, error) {
+	// Generate quick picks then validate the picks and stake
+	req.generatePicks(userId)
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+
+	// Place the picks on the next game still open for betting
+	var card *models.Card
+	var startTime time.Time
+	err := gameEngine.WithOpenGame(func(gameNum uint64) error {
+		startTime = gameEngine.GetNextGame()
+
+		return db.Transaction(func(tx *gorm.DB) (err error) {
+			newCard := req.toCard(gameNum, userId)
+			if err := checkLimits(tx, userId, []models.Card{newCard}); err != nil {
+				return err
+			}
+
+			card, err = submitCard(tx, newCard)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	notifyCardPlaced(gameEngine, *card)
+
 	// Return the card
-	ctx.JSON(http.StatusOK, cardToPickResponse(*card))
+	resp := cardToPickResponse(*card)
+	resp.StartTime = startTime.UnixMilli()
+	return &resp, nil
 }
 
 type PickRequest struct {
@@ -86,6 +132,14 @@ type PickRequest struct {
 	Picks        []uint8 `json:"picks"`
 	PricePerGame uint64  `json:"price_per_game"`
 	NumGames     uint8   `json:"number_games"`
+
+	// SystemSize turns the card into a system entry, Picks holds SystemSize
+	// numbers and every PicksPerGame sized combination of them is played.
+	SystemSize uint8 `json:"system_size,omitempty"`
+
+	// QuickPick asks the server to pick the numbers, Picks is ignored and
+	// replaced with a random selection of the right size.
+	QuickPick bool `json:"quick_pick,omitempty"`
 }
 
 var (
@@ -93,45 +147,262 @@ var (
 	ValidPickMax      uint8 = 80
 	ValidPicksPerGame       = []uint8{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 15, 20, 40}
 	ValidGames              = []uint8{1, 2, 3, 4, 5, 10, 20, 50, 100}
+
+	// System entries can't select more than ValidSystemSizeMax numbers or
+	// play more than MaxSystemCombinations combinations.
+	ValidSystemSizeMax    uint8  = 20
+	MaxSystemCombinations uint64 = 1_000
+
+	// Betting limits, stakes are the price per game. MaxGameLiability caps
+	// how much could be paid out on one game if every card on it won the
+	// top prize. These are the defaults, LoadBettingLimits replaces them
+	// from the betting config.
+	ValidStakeMin    uint64 = 1
+	ValidStakeMax    uint64 = 100
+	ValidStakes             = []uint64{1, 2, 3, 5, 10, 20, 25, 50, 100}
+	MaxCardTotal     uint64 = 2_000
+	MaxGameLiability uint64 = 10_000_000_000
 )
 
-func (p PickRequest) isValid() bool {
-	// Make sure all picks are in range 1-80
-	for _, num := range p.Picks {
-		if num < ValidPickMin || num > ValidPickMax {
-			return false
-		}
-	}
+// problems returns a detail for every pick rule the request breaks, so they
+// can all be reported at once.
+func (p PickRequest) problems() []ErrorDetail {
+	details := selectionProblems(p.Picks)
 
 	// Make sure the number of picks is valid
 	if !utils.Contains(ValidPicksPerGame, p.PicksPerGame) {
-		return false
+		details = append(details, ErrorDetail{
+			Field:   "picks_per_game",
+			Code:    DetailNotAllowed,
+			Message: fmt.Sprintf("%d numbers per game isn't allowed", p.PicksPerGame),
+		})
 	}
 
 	// Make sure the number of games is valid
 	if !utils.Contains(ValidGames, p.NumGames) {
-		return false
+		details = append(details, ErrorDetail{
+			Field:   "number_games",
+			Code:    DetailNotAllowed,
+			Message: fmt.Sprintf("%d games isn't allowed", p.NumGames),
+		})
+	}
+
+	// Check if numbers selected match the number of picks, system entries
+	// select SystemSize numbers instead
+	size := p.PicksPerGame
+	if p.SystemSize > 0 {
+		size = p.SystemSize
+
+		// System entries select more numbers than they play per combination
+		if p.SystemSize <= p.PicksPerGame || p.SystemSize > ValidSystemSizeMax {
+			details = append(details, ErrorDetail{
+				Field:   "system_size",
+				Code:    DetailOutOfRange,
+				Message: fmt.Sprintf("has to be more than picks_per_game and no more than %d", ValidSystemSizeMax),
+			})
+		} else if combinations := utils.Combinations(int(p.SystemSize), int(p.PicksPerGame)); combinations > MaxSystemCombinations {
+			details = append(details, ErrorDetail{
+				Field:   "system_size",
+				Code:    DetailTooMany,
+				Message: fmt.Sprintf("plays %d combinations, more than the %d allowed", combinations, MaxSystemCombinations),
+			})
+		}
+	}
+
+	if len(p.Picks) != int(size) {
+		details = append(details, ErrorDetail{
+			Field:   "picks",
+			Code:    DetailWrongCount,
+			Message: fmt.Sprintf("%d numbers picked but %d are needed", len(p.Picks), size),
+		})
+	}
+
+	return details
+}
+
+// selectionProblems returns a detail for every number in the selection which
+// is out of range or repeats an earlier one. Selections have to be a set, so a
+// card can't win more than its distinct numbers allow. Every request which
+// picks numbers is checked with it.
+func selectionProblems(picks []uint8) []ErrorDetail {
+	details := make([]ErrorDetail, 0)
+	firstSeen := make(map[uint8]int, len(picks))
+
+	for i, num := range picks {
+		// Make sure all picks are in range 1-80
+		if num < ValidPickMin || num > ValidPickMax {
+			details = append(details, ErrorDetail{
+				Field:   fmt.Sprintf("picks[%d]", i),
+				Code:    DetailOutOfRange,
+				Message: fmt.Sprintf("%d isn't between %d and %d", num, ValidPickMin, ValidPickMax),
+			})
+			continue
+		}
+
+		// Make sure each number is only picked once
+		if first, ok := firstSeen[num]; ok {
+			details = append(details, ErrorDetail{
+				Field:   fmt.Sprintf("picks[%d]", i),
+				Code:    DetailDuplicate,
+				Message: fmt.Sprintf("%d was already picked at picks[%d]", num, first),
+			})
+			continue
+		}
+		firstSeen[num] = i
+	}
+
+	return details
+}
+
+// generatePicks fills in the numbers for quick pick requests using the same
+// randomness as the draw. Requests which aren't quick picks are left alone.
+func (p *PickRequest) generatePicks(user string) {
+	if !p.QuickPick {
+		return
+	}
+
+	size := p.PicksPerGame
+	if p.SystemSize > 0 {
+		size = p.SystemSize
+	}
+
+	p.Picks = engine.QuickPick(int(size))
+
+	log.WithFields(log.Fields{
+		"src":   "api.generatePicks",
+		"user":  user,
+		"picks": fmt.Sprintf("%+v", p.Picks),
+	}).Info("Generated quick pick")
+}
+
+// validate checks the request against the pick rules and the betting limits.
+// Broken pick rules are all returned in the details of ErrInvalidPicks,
+// otherwise the APIError for the first betting limit broken is returned.
+func (p PickRequest) validate() error {
+	if details := p.problems(); len(details) > 0 {
+		return ErrInvalidPicks.WithDetails(details...)
+	}
+
+	return p.validateStake()
+}
+
+// validateStake checks the price per game and the total cost of the card
+// against the betting limits, returning the APIError for the first one broken.
+func (p PickRequest) validateStake() error {
+	if p.PricePerGame < ValidStakeMin {
+		return ErrStakeTooLow.WithDetails(ErrorDetail{
+			Field:   "price_per_game",
+			Code:    DetailOutOfRange,
+			Message: fmt.Sprintf("has to be at least %d", ValidStakeMin),
+		})
+	}
+
+	if p.PricePerGame > ValidStakeMax {
+		return ErrStakeTooHigh.WithDetails(ErrorDetail{
+			Field:   "price_per_game",
+			Code:    DetailOutOfRange,
+			Message: fmt.Sprintf("has to be no more than %d", ValidStakeMax),
+		})
+	}
+
+	if len(ValidStakes) > 0 && !utils.Contains(ValidStakes, p.PricePerGame) {
+		return ErrInvalidStake.WithDetails(ErrorDetail{
+			Field:   "price_per_game",
+			Code:    DetailNotAllowed,
+			Message: fmt.Sprintf("%d isn't an allowed stake", p.PricePerGame),
+		})
+	}
+
+	total, ok := p.toCard(0, "").TotalCost()
+	if !ok || total > MaxCardTotal {
+		return ErrCardTotalTooHigh
+	}
+
+	return nil
+}
+
+// submitCard places the card as long as it wouldn't push the liability of any
+// of its games over MaxGameLiability, or the guild's own liability. Every guild
+// shares the same draws, so MaxGameLiability counts the cards of every guild
+// while the guild's own liability only counts its cards. Pass a transaction as
+// db when the card has to be created alongside others.
+func submitCard(db *gorm.DB, card models.Card) (*models.Card, error) {
+	if _, ok := card.MaxPayout(); !ok {
+		return nil, ErrLiabilityExceeded
+	}
+
+	newCard, err := models.SubmitCard(db, card, models.LiabilityCaps{
+		Total:  MaxGameLiability,
+		Tenant: tenantConfig(db).MaxGameLiability,
+	})
+	if errors.Is(err, models.ErrLiabilityExceeded) || errors.Is(err, models.ErrPayoutOverflow) {
+		return nil, ErrLiabilityExceeded
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return newCard, nil
+}
+
+// toCard returns the card the request describes, starting on startGame.
+func (p PickRequest) toCard(startGame uint64, user string) models.Card {
+	card := models.Card{
+		Selection: p.Picks,
+		StartGame: startGame,
+		LastGame:  startGame + uint64(p.NumGames),
+		PerGame:   p.PricePerGame,
+		QuickPick: p.QuickPick,
+		User:      user,
+	}
+
+	if p.SystemSize > 0 {
+		card.SystemSpots = p.PicksPerGame
 	}
 
-	// Check if numbers selected match the number of picks
-	if uint8(len(p.Picks)) != p.PicksPerGame {
-		return false
+	return card
+}
+
+// cardToPickRequest returns the request which would place the card again, so
+// it can be checked against the current rules.
+func cardToPickRequest(card models.Card) PickRequest {
+	req := PickRequest{
+		PicksPerGame: card.Spots(),
+		Picks:        card.Selection,
+		PricePerGame: card.PerGame,
+		NumGames:     uint8(card.LastGame - card.StartGame),
 	}
 
-	return true
+	if card.SystemSpots > 0 {
+		req.SystemSize = uint8(len(card.Selection))
+	}
+
+	return req
 }
 
 type PickResponse struct {
-	CardId    uint64 `json:"card_id"`
-	StartGame uint64 `json:"start_game_num"`
-	LastGame  uint64 `json:"last_game_num"`
+	CardId       uint64 `json:"card_id"`
+	TicketRef    string `json:"ticket_ref"`
+	Selection    []int  `json:"selection"`
+	StartGame    uint64 `json:"start_game_num"`
+	LastGame     uint64 `json:"last_game_num"`
+	StartTime    int64  `json:"start_time,omitempty"`
+	Combinations uint64 `json:"combinations"`
+	TotalCost    uint64 `json:"total_cost"`
 }
 
 func cardToPickResponse(card models.Card) PickResponse {
+	// Cards are checked against MaxCardTotal when placed, so can't overflow
+	totalCost, _ := card.TotalCost()
+
 	resp := PickResponse{
-		CardId:    card.ID,
-		StartGame: card.StartGame,
-		LastGame:  card.LastGame,
+		CardId:       card.ID,
+		TicketRef:    card.TicketRef,
+		Selection:    utils.ToInts(card.Selection),
+		StartGame:    card.StartGame,
+		LastGame:     card.LastGame,
+		Combinations: card.Combinations(),
+		TotalCost:    totalCost,
 	}
 
 	return resp
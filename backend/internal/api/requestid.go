@@ -0,0 +1,52 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	REQUEST_ID_KEY    = "request_id"
+	REQUEST_ID_HEADER = "X-Request-Id"
+)
+
+// MaxRequestIdLength is the longest request ID accepted from a client
+var MaxRequestIdLength = 128
+
+// RequestID gives every request an ID, which is sent back in the X-Request-Id
+// header and in any error so a failed request can be found in the logs. An ID
+// sent by the client is kept so it can trace requests through its own systems,
+// as long as it's printable and not too long.
+func RequestID(ctx *gin.Context) {
+	requestId := ctx.GetHeader(REQUEST_ID_HEADER)
+	if !isValidRequestId(requestId) {
+		requestId = newRequestId()
+	}
+
+	ctx.Set(REQUEST_ID_KEY, requestId)
+	ctx.Header(REQUEST_ID_HEADER, requestId)
+	ctx.Next()
+}
+
+func isValidRequestId(requestId string) bool {
+	if len(requestId) == 0 || len(requestId) > MaxRequestIdLength {
+		return false
+	}
+
+	for _, c := range requestId {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// newRequestId returns a random 128 bit ID, hex encoded
+func newRequestId() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
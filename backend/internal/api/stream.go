@@ -1,9 +1,15 @@
 package api
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"keno/internal/engine"
 	"keno/internal/models"
+	"keno/internal/utils"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -18,22 +24,40 @@ var upgrader = websocket.Upgrader{
 	CheckOrigin:     func(r *http.Request) bool { return true },
 }
 
+// EventsHeartbeat is how often a comment is sent on the event stream, so
+// proxies don't close it while waiting for the next game.
+var EventsHeartbeat = 15 * time.Second
+
 // Stream Games Live
 // @Summary Stream the current game
 // @Description When a game is calculated and started, this endpoint will stream the game to the client. This will include all the picks which the client will have to display over 1.5 minutes for the proper effect.
+// @Description
+// @Description Pass your Discord token in the `Authorization` header to also get messages meant just for you. Clients which can't set headers, like browsers, can get a ticket from `POST /api/v1/stream-ticket` and pass it in the `ticket` query parameter instead. A `CARD` message confirms each card you place and a `WIN` message is sent for every card which wins something when a game is settled.
+// @Description
+// @Description Messages about players only come from the guild given in the `X-Guild-Id` header, or the guild the ticket was made in, or the default community if there isn't one. Anonymous sockets only get game updates.
 // @Tags games
 // @Produce json
+// @Param ticket query string false "Stream ticket, if the Authorization header can't be sent"
 // @Param Connection header string true "Connection: Upgrade"
 // @Param Upgrade header string true "Upgrade: websocket"
 // @Param Sec-Websocket-Version header string true "Sec-Websocket-Version: 13"
 // @Success 200 {object} models.Message
+// @Failure 401 {object} APIError
+// @Failure 403 {object} APIError
 // @Failure 500 {object} APIError
 // @Router /api/v1/ws [get]
 func GameStreamer(ctx *gin.Context) {
 	// Get the game engine from the context
 	gameEngine, ok := ctx.Get(engine.EngineKey)
 	if !ok {
-		ctx.JSON(500, ErrInternalError)
+		respondError(ctx, 500, ErrInternalError)
+		return
+	}
+
+	// Authenticate the socket if it was given a token or ticket, anonymous
+	// sockets only get game updates
+	userId, tenant, ok := streamUser(ctx, "api.GameStreamer")
+	if !ok {
 		return
 	}
 
@@ -50,6 +74,11 @@ func GameStreamer(ctx *gin.Context) {
 	gameEngine.(*engine.Engine).AddListener(listener)
 	defer gameEngine.(*engine.Engine).RemoveListener(listener)
 
+	if userId != "" {
+		gameEngine.(*engine.Engine).AddUserListener(tenant, userId, listener)
+		defer gameEngine.(*engine.Engine).RemoveUserListener(tenant, userId, listener)
+	}
+
 	// While connection is open
 	for {
 		select {
@@ -68,3 +97,165 @@ func GameStreamer(ctx *gin.Context) {
 		}
 	}
 }
+
+// Stream Games as Server-Sent Events
+// @Summary Stream the current game as Server-Sent Events
+// @Description The same game messages as the websocket, `NEW`, `PIC` and `CUR`, as Server-Sent Events for clients which can't use websockets. Each event's data is a message and its ID numbers the message.
+// @Description
+// @Description When reconnecting, send the ID of the last event received in the `Last-Event-ID` header, which EventSource does for you, to get the messages missed since. If they are too old to replay a `CUR` message is sent instead, like on a new connection. A comment is sent every 15 seconds to keep the connection open.
+// @Description
+// @Description Authenticate the stream the same way as the websocket, with the `Authorization` header or a `ticket`, to also get the messages meant just for you. Those events don't have an ID as they can't be replayed.
+// @Tags games
+// @Produce text/event-stream
+// @Param Last-Event-ID header string false "ID of the last event received"
+// @Param last_event_id query string false "ID of the last event received, if it can't be sent in the Last-Event-ID header"
+// @Param ticket query string false "Stream ticket, if the Authorization header can't be sent"
+// @Success 200 {object} models.Message
+// @Failure 401 {object} APIError
+// @Failure 403 {object} APIError
+// @Failure 500 {object} APIError
+// @Router /api/v1/events [get]
+func GameEvents(ctx *gin.Context) {
+	// Get the game engine from the context
+	gameEngine, ok := ctx.Get(engine.EngineKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	userId, tenant, ok := streamUser(ctx, "api.GameEvents")
+	if !ok {
+		return
+	}
+
+	lastEventId := ctx.GetHeader("Last-Event-ID")
+	if lastEventId == "" {
+		lastEventId = ctx.Query("last_event_id")
+	}
+
+	// Add the listener, with room for every message which could be replayed
+	listener := make(chan models.Message, engine.ReplaySize+10)
+	if lastSeq, err := strconv.ParseUint(lastEventId, 10, 64); err == nil {
+		gameEngine.(*engine.Engine).AddListenerAfter(listener, lastSeq)
+	} else {
+		gameEngine.(*engine.Engine).AddListener(listener)
+	}
+	defer gameEngine.(*engine.Engine).RemoveListener(listener)
+
+	if userId != "" {
+		gameEngine.(*engine.Engine).AddUserListener(tenant, userId, listener)
+		defer gameEngine.(*engine.Engine).RemoveUserListener(tenant, userId, listener)
+	}
+
+	// Stop proxies from buffering the stream
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Writer.WriteHeaderNow()
+	ctx.Writer.Flush()
+
+	heartbeat := time.NewTicker(EventsHeartbeat)
+	defer heartbeat.Stop()
+
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case message := <-listener:
+			data, err := json.Marshal(message)
+			if err != nil {
+				log.WithField("src", "api.GameEvents").WithError(err).Error("Error encoding game event")
+				return false
+			}
+
+			// Only game messages are numbered, the rest can't be resumed from
+			if message.Seq > 0 {
+				_, err = fmt.Fprintf(w, "id: %d\ndata: %s\n\n", message.Seq, data)
+			} else {
+				_, err = fmt.Fprintf(w, "data: %s\n\n", data)
+			}
+			return err == nil
+		case <-heartbeat.C:
+			_, err := io.WriteString(w, ": heartbeat\n\n")
+			return err == nil
+		case <-ctx.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+// streamUser authenticates a stream from the Authorization header, or from a
+// stream ticket for clients which can't set headers. Streams with neither are
+// anonymous and have no user. ok is false if the stream was refused, in which
+// case the error has already been sent.
+func streamUser(ctx *gin.Context, src string) (userId, tenant string, ok bool) {
+	if ticket := ctx.Query("ticket"); ticket != "" {
+		userId, tenant, ok = useStreamTicket(ticket)
+		if !ok {
+			log.WithField("src", src).Error("Stream opened with an invalid ticket")
+			respondError(ctx, http.StatusUnauthorized, ErrInvalidToken)
+		}
+		return userId, tenant, ok
+	}
+
+	authToken := ctx.GetHeader("Authorization")
+	if authToken == "" {
+		return "", models.DefaultTenant, true
+	}
+
+	userId, tenant, err := Authenticate(authToken, ctx.GetHeader(GUILD_HEADER))
+	switch {
+	case errors.Is(err, ErrInvalidToken):
+		log.WithField("src", src).Error("Stream opened with an invalid token")
+		respondError(ctx, http.StatusUnauthorized, ErrInvalidToken)
+		return "", "", false
+	case errors.Is(err, ErrInvalidGuild):
+		log.WithField("src", src).Error("Stream opened with an invalid guild")
+		respondError(ctx, http.StatusForbidden, ErrInvalidGuild)
+		return "", "", false
+	case err != nil:
+		log.WithField("src", src).WithError(err).Error("Error authenticating stream")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return "", "", false
+	}
+
+	return userId, tenant, true
+}
+
+// NotifyWinners returns a settlement hook which sends a WIN message to the
+// owner of every card which won something on the game. Syndicate members are
+// told about their part by PaySyndicates instead, and tournament cards only
+// win tournament points.
+func NotifyWinners(gameEngine *engine.Engine) func(game models.Game, results []models.CardResult) error {
+	return func(game models.Game, results []models.CardResult) error {
+		for _, result := range results {
+			if result.Prize == 0 || result.SyndicateID != 0 || result.TournamentID != 0 {
+				continue
+			}
+
+			gameEngine.NotifyUser(result.Tenant, result.User, models.GenerateMessage(models.WinMsg{
+				CardId:  result.CardID,
+				GameId:  result.GameID,
+				Matches: int(result.Matches),
+				Prize:   result.Prize,
+			}))
+		}
+
+		return nil
+	}
+}
+
+// notifyCardPlaced sends a CARD message to the card's owner confirming it was
+// placed.
+func notifyCardPlaced(gameEngine *engine.Engine, card models.Card) {
+	// Cards are checked against MaxCardTotal when placed, so can't overflow
+	totalCost, _ := card.TotalCost()
+
+	gameEngine.NotifyUser(card.Tenant, card.User, models.GenerateMessage(models.CardMsg{
+		CardId:    card.ID,
+		TicketRef: card.TicketRef,
+		Selection: utils.ToInts(card.Selection),
+		StartGame: card.StartGame,
+		LastGame:  card.LastGame,
+		TotalCost: totalCost,
+	}))
+}
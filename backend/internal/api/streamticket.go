@@ -0,0 +1,101 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"keno/internal/db"
+	"keno/internal/models"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// StreamTicketTTL is how long a stream ticket can be used for after it's made
+var StreamTicketTTL = 30 * time.Second
+
+// streamTicket is who a stream ticket was made for
+type streamTicket struct {
+	userId  string
+	tenant  string
+	expires time.Time
+}
+
+var (
+	streamTicketsMu sync.Mutex
+	streamTickets   = map[string]streamTicket{}
+)
+
+// Get Stream Ticket
+// @Summary Get a ticket to open the game stream with
+// @Description Get a short lived ticket which authenticates the websocket or event stream, for clients which can't send the `Authorization` header when opening them. Pass it as the `ticket` query parameter within 30 seconds, each ticket can only be used once. The stream gets the messages meant for you in the guild the ticket was made in.
+// @Tags games
+// @Produce json
+// @Success 200 {object} StreamTicketResponse
+// @Failure 401 {object} APIError
+// @Failure 500 {object} APIError
+// @Router /api/v1/stream-ticket [post]
+func CreateStreamTicket(ctx *gin.Context) {
+	// Get the database from the context, it's scoped to the user's tenant
+	database, ok := ctx.Get(db.DbKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+	tenant, _ := models.GetTenant(database.(*gorm.DB))
+
+	id := make([]byte, 32)
+	if _, err := rand.Read(id); err != nil {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+	ticket := hex.EncodeToString(id)
+	expires := time.Now().Add(StreamTicketTTL)
+
+	streamTicketsMu.Lock()
+	defer streamTicketsMu.Unlock()
+
+	// Clear out the tickets which were never used
+	for key, t := range streamTickets {
+		if time.Now().After(t.expires) {
+			delete(streamTickets, key)
+		}
+	}
+
+	streamTickets[ticket] = streamTicket{
+		userId:  ctx.GetString(USER_ID_KEY),
+		tenant:  tenant,
+		expires: expires,
+	}
+
+	ctx.JSON(http.StatusOK, StreamTicketResponse{
+		Ticket:    ticket,
+		ExpiresAt: expires.UnixMilli(),
+	})
+}
+
+// useStreamTicket returns who the ticket was made for and stops it being used
+// again, ok is false if it isn't a ticket or has expired.
+func useStreamTicket(ticket string) (userId, tenant string, ok bool) {
+	streamTicketsMu.Lock()
+	defer streamTicketsMu.Unlock()
+
+	t, ok := streamTickets[ticket]
+	if !ok {
+		return "", "", false
+	}
+	delete(streamTickets, ticket)
+
+	if time.Now().After(t.expires) {
+		return "", "", false
+	}
+
+	return t.userId, t.tenant, true
+}
+
+type StreamTicketResponse struct {
+	Ticket    string `json:"ticket"`
+	ExpiresAt int64  `json:"expires_at"`
+}
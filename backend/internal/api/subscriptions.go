@@ -0,0 +1,367 @@
+package api
+
+import (
+	"errors"
+	"keno/internal/db"
+	"keno/internal/engine"
+	"keno/internal/models"
+	"keno/internal/utils"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	MaxSubscriptions            = 10
+	MaxSubscriptionEvery uint64 = 100
+
+	// RenewalTime is how long the draw waits for subscriptions to renew
+	RenewalTime = 2 * time.Second
+)
+
+// errBettingClosed stops renewals once the draw of their game has started
+var errBettingClosed = errors.New("betting has closed")
+
+// Subscribe
+// @Summary Place the same card automatically every game
+// @Description Subscribe to have a single game card placed for you with the same selection and stake on every game, or every `every` games, up to `100`. The card follows the same rules as placing picks and is placed just before betting on the game closes.
+// @Description
+// @Description The subscription keeps going until you cancel it, until the next card would take the total staked over `budget`, or until the total won reaches `win_threshold`. Leave either out to not have that limit. Every card placed, or skipped, is logged to your history.
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param subscription body SubscriptionRequest true "The card to place and when to stop"
+// @Success 200 {object} SubscriptionResponse
+// @Failure 400 {object} APIError
+// @Failure 500 {object} APIError
+// @Router /api/v1/subscriptions [post]
+func Subscribe(ctx *gin.Context) {
+	req := SubscriptionRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		log.WithField("src", "api.Subscribe").Error("Subscribe call made with invalid JSON body")
+		respondError(ctx, http.StatusBadRequest, bindError(ErrInvalidSubscription, err))
+		return
+	}
+
+	userId := ctx.GetString(USER_ID_KEY)
+
+	// The subscription places single game cards, so check one of those
+	pickReq := req.toPickRequest()
+	pickReq.generatePicks(userId)
+	if err := pickReq.validate(); err != nil {
+		log.WithField("src", "api.Subscribe").WithError(err).Error("Subscribe call made with invalid picks")
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	cost, _ := pickReq.toCard(0, userId).CostPerGame()
+	if req.Every == 0 || req.Every > MaxSubscriptionEvery || (req.Budget > 0 && req.Budget < cost) {
+		log.WithField("src", "api.Subscribe").Error("Subscribe call made with invalid values")
+		respondError(ctx, http.StatusBadRequest, ErrInvalidSubscription)
+		return
+	}
+
+	// Get the database from the context
+	db, ok := ctx.Get(db.DbKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	// Get the game engine from the context
+	gameEngine, ok := ctx.Get(engine.EngineKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	subscriptions, err := models.GetSubscriptions(db.(*gorm.DB), userId)
+	if err != nil {
+		log.WithField("src", "api.Subscribe").WithError(err).Error("Error getting subscriptions")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	active := 0
+	for _, subscription := range subscriptions {
+		if subscription.Status == models.SubscriptionActive {
+			active++
+		}
+	}
+
+	if active >= MaxSubscriptions {
+		respondError(ctx, http.StatusBadRequest, ErrTooManySubscriptions)
+		return
+	}
+
+	// Start from the next game open for betting
+	card := pickReq.toCard(0, userId)
+	subscription, err := models.CreateSubscription(db.(*gorm.DB), models.Subscription{
+		Selection:    card.Selection,
+		SystemSpots:  card.SystemSpots,
+		PerGame:      card.PerGame,
+		Every:        req.Every,
+		NextGame:     gameEngine.(*engine.Engine).GetNextOpenGame(),
+		Budget:       req.Budget,
+		WinThreshold: req.WinThreshold,
+		User:         userId,
+	})
+	if err != nil {
+		log.WithField("src", "api.Subscribe").WithError(err).Error("Error creating subscription")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, subscriptionToResponse(*subscription))
+}
+
+// List Subscriptions
+// @Summary List your subscriptions
+// @Description Get every subscription you have made, including ones which have stopped.
+// @Tags subscriptions
+// @Produce json
+// @Success 200 {array} SubscriptionResponse
+// @Failure 500 {object} APIError
+// @Router /api/v1/subscriptions [get]
+func ListSubscriptions(ctx *gin.Context) {
+	// Get the database from the context
+	db, ok := ctx.Get(db.DbKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	subscriptions, err := models.GetSubscriptions(db.(*gorm.DB), ctx.GetString(USER_ID_KEY))
+	if err != nil {
+		log.WithField("src", "api.ListSubscriptions").WithError(err).Error("Error getting subscriptions")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	resp := make([]SubscriptionResponse, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		resp = append(resp, subscriptionToResponse(subscription))
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// Cancel Subscription
+// @Summary Cancel a subscription
+// @Description Stop a subscription from placing any more cards. Cards it has already placed are kept.
+// @Tags subscriptions
+// @param subscription_id path int true "Subscription ID"
+// @Produce json
+// @Success 200 {object} SubscriptionResponse
+// @Failure 400 {object} APIError
+// @Failure 404 {object} APIError
+// @Failure 409 {object} APIError
+// @Failure 500 {object} APIError
+// @Router /api/v1/subscriptions/{subscription_id} [delete]
+func CancelSubscription(ctx *gin.Context) {
+	subscriptionId, err := strconv.ParseUint(ctx.Param("subscription_id"), 10, 64)
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, ErrInvalidSubscription)
+		return
+	}
+
+	// Get the database from the context
+	db, ok := ctx.Get(db.DbKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	// Get the subscription, only the owner can cancel it
+	subscription, err := models.GetSubscription(db.(*gorm.DB), subscriptionId)
+	if err != nil || subscription.User != ctx.GetString(USER_ID_KEY) {
+		respondError(ctx, http.StatusNotFound, ErrInvalidSubscription)
+		return
+	}
+
+	err = models.StopSubscription(db.(*gorm.DB), subscription, models.SubscriptionCancelled)
+	if errors.Is(err, models.ErrSubscriptionInactive) {
+		respondError(ctx, http.StatusConflict, ErrSubscriptionInactive)
+		return
+	}
+	if err != nil {
+		log.WithField("src", "api.CancelSubscription").WithError(err).Error("Error cancelling subscription")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, subscriptionToResponse(*subscription))
+}
+
+// RenewSubscriptions returns the game start hook which places the cards for
+// every subscription due on the game, before betting on it closes. The
+// renewals run in the background and the draw only waits RenewalTime for them,
+// any which aren't placed by the time betting closes are placed on a later
+// game instead.
+func RenewSubscriptions(db *gorm.DB, gameEngine *engine.Engine) func(gameNum uint64) {
+	var running sync.Mutex
+
+	return func(gameNum uint64) {
+		// Renewals from the last game are stopping now its draw has started,
+		// the subscriptions they didn't get to are still due
+		if !running.TryLock() {
+			log.WithField("src", "api.RenewSubscriptions").Warn("Still renewing subscriptions for the last game")
+			return
+		}
+
+		done := make(chan struct{})
+		go func() {
+			defer running.Unlock()
+			defer close(done)
+			renewDueSubscriptions(db, gameEngine, gameNum)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(RenewalTime):
+			log.WithFields(log.Fields{
+				"src":  "api.RenewSubscriptions",
+				"game": gameNum,
+			}).Warn("Subscriptions took too long to renew, the rest will be placed on a later game")
+		}
+	}
+}
+
+// renewDueSubscriptions renews every subscription due on gameNum, stopping as
+// soon as betting on it closes.
+func renewDueSubscriptions(db *gorm.DB, gameEngine *engine.Engine, gameNum uint64) {
+	subscriptions, err := models.GetDueSubscriptions(db, gameNum)
+	if err != nil {
+		log.WithField("src", "api.RenewSubscriptions").WithError(err).Error("Error getting due subscriptions")
+		return
+	}
+
+	for i := range subscriptions {
+		// Place the card in the subscription's own tenant, while betting is
+		// still open on the game
+		err := gameEngine.WithOpenGame(func(openGame uint64) error {
+			if openGame != gameNum {
+				return errBettingClosed
+			}
+
+			return db.Transaction(func(tx *gorm.DB) error {
+				return renewSubscription(models.ForTenant(tx, subscriptions[i].Tenant), &subscriptions[i], gameNum)
+			})
+		})
+		if errors.Is(err, errBettingClosed) {
+			return
+		}
+		if err != nil {
+			log.WithFields(log.Fields{
+				"src":          "api.RenewSubscriptions",
+				"subscription": subscriptions[i].ID,
+				"game":         gameNum,
+			}).WithError(err).Error("Error renewing subscription")
+		}
+	}
+}
+
+// renewSubscription places the subscription's card on gameNum, unless it has
+// reached its win threshold or budget in which case it's stopped instead.
+func renewSubscription(db *gorm.DB, subscription *models.Subscription, gameNum uint64) error {
+	// Every game before this one has been drawn, so its winnings are known
+	if err := models.UpdateSubscriptionWinnings(db, subscription, gameNum); err != nil {
+		return err
+	}
+
+	if subscription.WinThreshold > 0 && subscription.Won >= subscription.WinThreshold {
+		return models.StopSubscription(db, subscription, models.SubscriptionThresholdHit)
+	}
+
+	card := subscription.Card(gameNum)
+	cost, ok := card.CostPerGame()
+	if !ok {
+		return errors.New("subscription cost overflowed")
+	}
+
+	spent, ok := utils.AddUint64(subscription.Spent, cost)
+	if subscription.Budget > 0 && (!ok || spent > subscription.Budget) {
+		return models.StopSubscription(db, subscription, models.SubscriptionBudgetSpent)
+	}
+
+	// Hold the card to the same rules as any other, the limits may have
+	// changed since the subscription was made
+	err := cardToPickRequest(card).validate()
+	if err == nil {
+		err = checkLimits(db, subscription.User, []models.Card{card})
+	}
+
+	var newCard *models.Card
+	if err == nil {
+		newCard, err = submitCard(db, card)
+	}
+
+	var apiErr APIError
+	if errors.As(err, &apiErr) {
+		return models.SkipRenewal(db, subscription, gameNum, apiErr.Code)
+	}
+	if err != nil {
+		return err
+	}
+
+	return models.RecordRenewal(db, subscription, newCard, cost)
+}
+
+type SubscriptionRequest struct {
+	PicksPerGame uint8   `json:"picks_per_game"`
+	Picks        []uint8 `json:"picks"`
+	PricePerGame uint64  `json:"price_per_game"`
+	SystemSize   uint8   `json:"system_size,omitempty"`
+	QuickPick    bool    `json:"quick_pick,omitempty"`
+
+	Every        uint64 `json:"every"`
+	Budget       uint64 `json:"budget,omitempty"`
+	WinThreshold uint64 `json:"win_threshold,omitempty"`
+}
+
+func (r SubscriptionRequest) toPickRequest() PickRequest {
+	return PickRequest{
+		PicksPerGame: r.PicksPerGame,
+		Picks:        r.Picks,
+		PricePerGame: r.PricePerGame,
+		NumGames:     1,
+		SystemSize:   r.SystemSize,
+		QuickPick:    r.QuickPick,
+	}
+}
+
+type SubscriptionResponse struct {
+	SubscriptionId uint64 `json:"subscription_id"`
+	Selection      []int  `json:"selection"`
+	PicksPerGame   uint8  `json:"picks_per_game"`
+	PricePerGame   uint64 `json:"price_per_game"`
+	Every          uint64 `json:"every"`
+	NextGame       uint64 `json:"next_game_num"`
+	Budget         uint64 `json:"budget"`
+	Spent          uint64 `json:"spent"`
+	WinThreshold   uint64 `json:"win_threshold"`
+	Won            uint64 `json:"won"`
+	Status         string `json:"status"`
+}
+
+func subscriptionToResponse(subscription models.Subscription) SubscriptionResponse {
+	return SubscriptionResponse{
+		SubscriptionId: subscription.ID,
+		Selection:      utils.ToInts(subscription.Selection),
+		PicksPerGame:   subscription.Card(0).Spots(),
+		PricePerGame:   subscription.PerGame,
+		Every:          subscription.Every,
+		NextGame:       subscription.NextGame,
+		Budget:         subscription.Budget,
+		Spent:          subscription.Spent,
+		WinThreshold:   subscription.WinThreshold,
+		Won:            subscription.Won,
+		Status:         subscription.Status,
+	}
+}
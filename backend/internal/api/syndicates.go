@@ -0,0 +1,571 @@
+package api
+
+import (
+	"errors"
+	"keno/internal/db"
+	"keno/internal/engine"
+	"keno/internal/models"
+	"keno/internal/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	MinSyndicateShares     uint64 = 2
+	MaxSyndicateShares     uint64 = 100
+	MaxSyndicateNameLength        = 32
+)
+
+// Start a Syndicate
+// @Summary Start a syndicate card which others can buy shares in
+// @Description Set up a card to be bought between several players. The card follows the same rules as placing picks and its total cost is split into `shares` equal shares, so the total cost has to divide evenly between them. You buy `buy_shares` of them straight away, at least one.
+// @Description
+// @Description Anyone can buy the rest of the shares, and as soon as the last one is sold the card is placed on the next game open for betting. Whatever it wins is split between the members by how many shares they hold, rounded down, with anything left over going to whoever started the syndicate.
+// @Tags syndicates
+// @Accept json
+// @Produce json
+// @Param Idempotency-Key header string false "Unique key for the request, retries with the same key replay the first response instead of placing another card"
+// @Param syndicate body SyndicateRequest true "The card to place and how to split it"
+// @Success 200 {object} SyndicateResponse
+// @Failure 400 {object} APIError
+// @Failure 403 {object} APIError
+// @Failure 409 {object} APIError
+// @Failure 500 {object} APIError
+// @Router /api/v1/syndicates [post]
+func StartSyndicate(ctx *gin.Context) {
+	req := SyndicateRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		log.WithField("src", "api.StartSyndicate").Error("Syndicate call made with invalid JSON body")
+		respondError(ctx, http.StatusBadRequest, bindError(ErrInvalidSyndicate, err))
+		return
+	}
+
+	userId := ctx.GetString(USER_ID_KEY)
+
+	// Generate quick picks then validate the card
+	req.Card.generatePicks(userId)
+	if err := req.Card.validate(); err != nil {
+		log.WithField("src", "api.StartSyndicate").WithError(err).Error("Syndicate call made with invalid picks")
+		respondError(ctx, http.StatusBadRequest, toAPIError(err).WithFieldPrefix("card."))
+		return
+	}
+
+	// The card is checked against MaxCardTotal so can't overflow
+	card := req.Card.toCard(0, userId)
+	total, _ := card.TotalCost()
+	if len(req.Name) == 0 || len(req.Name) > MaxSyndicateNameLength ||
+		req.Shares < MinSyndicateShares || req.Shares > MaxSyndicateShares || total%req.Shares != 0 ||
+		req.BuyShares == 0 || req.BuyShares > req.Shares {
+		log.WithField("src", "api.StartSyndicate").Error("Syndicate call made with invalid values")
+		respondError(ctx, http.StatusBadRequest, ErrInvalidSyndicate)
+		return
+	}
+
+	// Get the database from the context
+	db, ok := ctx.Get(db.DbKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	// Get the game engine from the context
+	gameEngine, ok := ctx.Get(engine.EngineKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	// Buying every share places the card straight away
+	var syndicate *models.Syndicate
+	var placed *models.Card
+	err := gameEngine.(*engine.Engine).WithOpenGame(func(gameNum uint64) error {
+		return db.(*gorm.DB).Transaction(func(tx *gorm.DB) (err error) {
+			syndicate, err = models.CreateSyndicate(tx, models.Syndicate{
+				Name:        req.Name,
+				Selection:   card.Selection,
+				SystemSpots: card.SystemSpots,
+				PerGame:     card.PerGame,
+				NumGames:    card.LastGame - card.StartGame,
+				Shares:      req.Shares,
+				SharePrice:  total / req.Shares,
+				User:        userId,
+			})
+			if err != nil {
+				return err
+			}
+
+			if err := buyShares(tx, syndicate, userId, req.BuyShares); err != nil {
+				return err
+			}
+			if syndicate.SharesSold < syndicate.Shares {
+				return nil
+			}
+
+			// If the card is refused the syndicate is never started at all
+			placed, err = placeSyndicate(tx, syndicate, gameNum)
+			return err
+		})
+	})
+
+	var apiErr APIError
+	if errors.As(err, &apiErr) {
+		log.WithField("src", "api.StartSyndicate").WithError(err).Error("Syndicate call blocked by a limit")
+		respondError(ctx, errorStatus(apiErr), apiErr)
+		return
+	}
+	if err != nil {
+		log.WithField("src", "api.StartSyndicate").WithError(err).Error("Error starting syndicate")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	if placed != nil {
+		notifyCardPlaced(gameEngine.(*engine.Engine), *placed)
+	}
+
+	ctx.JSON(http.StatusOK, syndicateToResponse(*syndicate))
+}
+
+// List Syndicates
+// @Summary List the syndicates with shares for sale
+// @Description Get every syndicate which is still selling shares, oldest first.
+// @Tags syndicates
+// @Produce json
+// @Success 200 {array} SyndicateResponse
+// @Failure 500 {object} APIError
+// @Router /api/v1/syndicates [get]
+func ListSyndicates(ctx *gin.Context) {
+	// Get the database from the context
+	db, ok := ctx.Get(db.DbKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	syndicates, err := models.GetOpenSyndicates(db.(*gorm.DB))
+	if err != nil {
+		log.WithField("src", "api.ListSyndicates").WithError(err).Error("Error getting syndicates")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	resp := make([]SyndicateResponse, 0, len(syndicates))
+	for _, syndicate := range syndicates {
+		resp = append(resp, syndicateToResponse(syndicate))
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// Get a Syndicate
+// @Summary Get a syndicate with its members and payouts
+// @Description Get a syndicate along with how many shares each member holds and everything it has paid out so far.
+// @Tags syndicates
+// @param syndicate_id path int true "Syndicate ID"
+// @Produce json
+// @Success 200 {object} SyndicateDetailResponse
+// @Failure 400 {object} APIError
+// @Failure 404 {object} APIError
+// @Failure 500 {object} APIError
+// @Router /api/v1/syndicates/{syndicate_id} [get]
+func GetSyndicate(ctx *gin.Context) {
+	syndicateId, err := strconv.ParseUint(ctx.Param("syndicate_id"), 10, 64)
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, ErrInvalidSyndicate)
+		return
+	}
+
+	// Get the database from the context
+	db, ok := ctx.Get(db.DbKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	syndicate, err := models.GetSyndicate(db.(*gorm.DB), syndicateId)
+	if err != nil {
+		respondError(ctx, http.StatusNotFound, ErrInvalidSyndicate)
+		return
+	}
+
+	shares, err := models.GetSyndicateShares(db.(*gorm.DB), syndicate.ID)
+	if err != nil {
+		log.WithField("src", "api.GetSyndicate").WithError(err).Error("Error getting syndicate shares")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	payouts, err := models.GetSyndicatePayouts(db.(*gorm.DB), syndicate.ID)
+	if err != nil {
+		log.WithField("src", "api.GetSyndicate").WithError(err).Error("Error getting syndicate payouts")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	resp := SyndicateDetailResponse{
+		SyndicateResponse: syndicateToResponse(*syndicate),
+		Members:           make([]SyndicateMember, 0),
+		Payouts:           make([]SyndicatePayoutResponse, 0, len(payouts)),
+	}
+
+	// Add up the shares each member has bought
+	members := make(map[string]int)
+	for _, share := range shares {
+		i, ok := members[share.User]
+		if !ok {
+			i = len(resp.Members)
+			members[share.User] = i
+			resp.Members = append(resp.Members, SyndicateMember{User: share.User})
+		}
+
+		resp.Members[i].Shares += share.Shares
+		resp.Members[i].Cost += share.Cost
+	}
+
+	for _, payout := range payouts {
+		resp.Payouts = append(resp.Payouts, SyndicatePayoutResponse{
+			GameId: payout.GameID,
+			User:   payout.User,
+			Shares: payout.Shares,
+			Amount: payout.Amount,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// Buy Syndicate Shares
+// @Summary Buy shares in a syndicate
+// @Description Buy shares in a syndicate which is still open. Buying the last share places the syndicate's card on the next game open for betting, if the card is refused the syndicate is cancelled and every share is refunded. Your responsible gambling limits apply to the cost of the shares.
+// @Tags syndicates
+// @Accept json
+// @Produce json
+// @param syndicate_id path int true "Syndicate ID"
+// @Param Idempotency-Key header string false "Unique key for the request, retries with the same key replay the first response instead of placing another card"
+// @Param shares body BuySharesRequest true "How many shares to buy"
+// @Success 200 {object} SyndicateResponse
+// @Failure 400 {object} APIError
+// @Failure 403 {object} APIError
+// @Failure 404 {object} APIError
+// @Failure 409 {object} APIError
+// @Failure 500 {object} APIError
+// @Router /api/v1/syndicates/{syndicate_id}/shares [post]
+func BuySyndicateShares(ctx *gin.Context) {
+	syndicateId, err := strconv.ParseUint(ctx.Param("syndicate_id"), 10, 64)
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, ErrInvalidSyndicate)
+		return
+	}
+
+	req := BuySharesRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil || req.Shares == 0 {
+		log.WithField("src", "api.BuySyndicateShares").Error("Buy shares call made with invalid body")
+		respondError(ctx, http.StatusBadRequest, bindError(ErrInvalidSyndicate, err))
+		return
+	}
+
+	// Get the database from the context
+	db, ok := ctx.Get(db.DbKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	// Get the game engine from the context
+	gameEngine, ok := ctx.Get(engine.EngineKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	syndicate, err := models.GetSyndicate(db.(*gorm.DB), syndicateId)
+	if err != nil {
+		respondError(ctx, http.StatusNotFound, ErrInvalidSyndicate)
+		return
+	}
+
+	var placed *models.Card
+	err = gameEngine.(*engine.Engine).WithOpenGame(func(gameNum uint64) (err error) {
+		placed, err = buyAndPlace(db.(*gorm.DB), syndicate, ctx.GetString(USER_ID_KEY), req.Shares, gameNum)
+		return err
+	})
+	if errors.Is(err, models.ErrSyndicateClosed) {
+		respondError(ctx, http.StatusConflict, ErrSyndicateClosed)
+		return
+	}
+	if errors.Is(err, models.ErrNotEnoughShares) {
+		respondError(ctx, http.StatusConflict, ErrNotEnoughShares)
+		return
+	}
+
+	var apiErr APIError
+	if errors.As(err, &apiErr) {
+		log.WithField("src", "api.BuySyndicateShares").WithError(err).Error("Buy shares call blocked by a limit")
+		respondError(ctx, errorStatus(apiErr), apiErr)
+		return
+	}
+	if err != nil {
+		log.WithField("src", "api.BuySyndicateShares").WithError(err).Error("Error buying shares")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	if placed != nil {
+		notifyCardPlaced(gameEngine.(*engine.Engine), *placed)
+	}
+
+	ctx.JSON(http.StatusOK, syndicateToResponse(*syndicate))
+}
+
+// Cancel Syndicate
+// @Summary Cancel a syndicate which hasn't been placed
+// @Description Stop selling shares in a syndicate you started. Only syndicates which are still open can be cancelled, and every share sold is refunded.
+// @Tags syndicates
+// @param syndicate_id path int true "Syndicate ID"
+// @Produce json
+// @Success 200 {object} SyndicateResponse
+// @Failure 400 {object} APIError
+// @Failure 404 {object} APIError
+// @Failure 409 {object} APIError
+// @Failure 500 {object} APIError
+// @Router /api/v1/syndicates/{syndicate_id} [delete]
+func CancelSyndicate(ctx *gin.Context) {
+	syndicateId, err := strconv.ParseUint(ctx.Param("syndicate_id"), 10, 64)
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, ErrInvalidSyndicate)
+		return
+	}
+
+	// Get the database from the context
+	db, ok := ctx.Get(db.DbKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	// Get the syndicate, only whoever started it can cancel it
+	syndicate, err := models.GetSyndicate(db.(*gorm.DB), syndicateId)
+	if err != nil || syndicate.User != ctx.GetString(USER_ID_KEY) {
+		respondError(ctx, http.StatusNotFound, ErrInvalidSyndicate)
+		return
+	}
+
+	err = models.CancelSyndicate(db.(*gorm.DB), syndicate)
+	if errors.Is(err, models.ErrSyndicateClosed) {
+		respondError(ctx, http.StatusConflict, ErrSyndicateClosed)
+		return
+	}
+	if err != nil {
+		log.WithField("src", "api.CancelSyndicate").WithError(err).Error("Error cancelling syndicate")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, syndicateToResponse(*syndicate))
+}
+
+// buyShares sells the user shares in the syndicate as long as their limits
+// allow it. It should be run in a transaction.
+func buyShares(db *gorm.DB, syndicate *models.Syndicate, user string, shares uint64) error {
+	if err := checkStakes(db, user, []uint64{shares * syndicate.SharePrice}); err != nil {
+		return err
+	}
+
+	_, err := models.BuyShares(db, syndicate, user, shares)
+	return err
+}
+
+// placeSyndicate places the card for a syndicate which has sold all its shares
+// on gameNum. It should be run in the same transaction as the last shares are
+// bought in, so the syndicate is never left sold out without its card.
+func placeSyndicate(db *gorm.DB, syndicate *models.Syndicate, gameNum uint64) (*models.Card, error) {
+	card, err := submitCard(db, syndicate.Card(gameNum))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := models.PlaceSyndicate(db, syndicate, card); err != nil {
+		return nil, err
+	}
+
+	return card, nil
+}
+
+// buyAndPlace sells the user shares in the syndicate, placing its card on
+// gameNum if they were the last ones. The shares have to be paid for before
+// the card can be placed, so if the card is refused the shares are bought
+// anyway and the syndicate is cancelled to refund every one of them rather
+// than being left open with nothing left to sell. Either way the shares and
+// what becomes of the syndicate are saved together.
+func buyAndPlace(db *gorm.DB, syndicate *models.Syndicate, user string, shares, gameNum uint64) (*models.Card, error) {
+	before := *syndicate
+
+	var card *models.Card
+	soldOut := false
+	err := db.Transaction(func(tx *gorm.DB) (err error) {
+		if err := buyShares(tx, syndicate, user, shares); err != nil {
+			return err
+		}
+		if syndicate.SharesSold < syndicate.Shares {
+			return nil
+		}
+
+		soldOut = true
+		card, err = placeSyndicate(tx, syndicate, gameNum)
+		return err
+	})
+
+	var refused APIError
+	if !soldOut || !errors.As(err, &refused) {
+		return card, err
+	}
+
+	log.WithField("src", "api.buyAndPlace").WithError(err).Warn("Syndicate card couldn't be placed, cancelling it")
+	*syndicate = before
+	cancelErr := db.Transaction(func(tx *gorm.DB) error {
+		if err := buyShares(tx, syndicate, user, shares); err != nil {
+			return err
+		}
+
+		return models.CancelSyndicate(tx, syndicate)
+	})
+	if cancelErr != nil {
+		log.WithField("src", "api.buyAndPlace").WithError(cancelErr).Error("Error cancelling syndicate")
+		return nil, cancelErr
+	}
+
+	return nil, err
+}
+
+// PaySyndicates returns a settlement hook which splits what each syndicate card
+// won on the game between its members, and sends each of them a WIN message
+// with their part. Stakes refunded for an abandoned game are split the same
+// way.
+func PaySyndicates(db *gorm.DB, gameEngine *engine.Engine) func(game models.Game, results []models.CardResult) error {
+	return func(game models.Game, results []models.CardResult) error {
+		var failed error
+		for _, result := range results {
+			if result.SyndicateID == 0 || result.Prize == 0 && result.Refund == 0 {
+				continue
+			}
+
+			payouts, err := paySyndicate(models.ForTenant(db, result.Tenant), result)
+			if err != nil {
+				log.WithFields(log.Fields{
+					"src":       "api.PaySyndicates",
+					"syndicate": result.SyndicateID,
+					"game":      game.ID,
+				}).WithError(err).Error("Error paying syndicate")
+				failed = errors.Join(failed, err)
+				continue
+			}
+
+			// Refunds aren't wins, so the members aren't told about them
+			if result.Refund > 0 {
+				continue
+			}
+
+			for _, payout := range payouts {
+				gameEngine.NotifyUser(payout.Tenant, payout.User, models.GenerateMessage(models.WinMsg{
+					CardId:  result.CardID,
+					GameId:  result.GameID,
+					Matches: int(result.Matches),
+					Prize:   payout.Amount,
+				}))
+			}
+		}
+
+		return failed
+	}
+}
+
+func paySyndicate(db *gorm.DB, result models.CardResult) ([]models.SyndicatePayout, error) {
+	syndicate, err := models.GetSyndicate(db, result.SyndicateID)
+	if err != nil {
+		return nil, err
+	}
+
+	shares, err := models.GetSyndicateShares(db, syndicate.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The stake refunded for an abandoned game is shared out like a prize
+	if result.Refund > 0 {
+		result.Prize = result.Refund
+	}
+
+	payouts := models.SplitPrize(*syndicate, result, shares)
+	if err := models.SavePayouts(db, payouts); err != nil {
+		return nil, err
+	}
+
+	return payouts, nil
+}
+
+type SyndicateRequest struct {
+	Name      string      `json:"name"`
+	Card      PickRequest `json:"card"`
+	Shares    uint64      `json:"shares"`
+	BuyShares uint64      `json:"buy_shares"`
+}
+
+type BuySharesRequest struct {
+	Shares uint64 `json:"shares"`
+}
+
+type SyndicateResponse struct {
+	SyndicateId  uint64 `json:"syndicate_id"`
+	Name         string `json:"name"`
+	Selection    []int  `json:"selection"`
+	Spots        uint8  `json:"spots"`
+	PricePerGame uint64 `json:"price_per_game"`
+	NumGames     uint64 `json:"number_games"`
+	Shares       uint64 `json:"shares"`
+	SharePrice   uint64 `json:"share_price"`
+	SharesSold   uint64 `json:"shares_sold"`
+	Status       string `json:"status"`
+	CardId       uint64 `json:"card_id,omitempty"`
+	User         string `json:"user"`
+}
+
+type SyndicateDetailResponse struct {
+	SyndicateResponse
+	Members []SyndicateMember         `json:"members"`
+	Payouts []SyndicatePayoutResponse `json:"payouts"`
+}
+
+type SyndicateMember struct {
+	User   string `json:"user"`
+	Shares uint64 `json:"shares"`
+	Cost   uint64 `json:"cost"`
+}
+
+type SyndicatePayoutResponse struct {
+	GameId uint64 `json:"game_id"`
+	User   string `json:"user"`
+	Shares uint64 `json:"shares"`
+	Amount uint64 `json:"amount"`
+}
+
+func syndicateToResponse(syndicate models.Syndicate) SyndicateResponse {
+	return SyndicateResponse{
+		SyndicateId:  syndicate.ID,
+		Name:         syndicate.Name,
+		Selection:    utils.ToInts(syndicate.Selection),
+		Spots:        syndicate.Card(0).Spots(),
+		PricePerGame: syndicate.PerGame,
+		NumGames:     syndicate.NumGames,
+		Shares:       syndicate.Shares,
+		SharePrice:   syndicate.SharePrice,
+		SharesSold:   syndicate.SharesSold,
+		Status:       syndicate.Status,
+		CardId:       syndicate.CardID,
+		User:         syndicate.User,
+	}
+}
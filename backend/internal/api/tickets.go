@@ -0,0 +1,95 @@
+package api
+
+import (
+	"errors"
+	"keno/internal/db"
+	"keno/internal/models"
+	"keno/internal/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Look up a Ticket
+// @Summary Look up a card by its ticket reference
+// @Description Anyone with a ticket reference can see the card's selection and how it has done on every game settled so far, without signing in. References are signed, so forged or mistyped ones are rejected.
+// @Tags cards
+// @param ticket_ref path string true "Ticket reference"
+// @Produce json
+// @Success 200 {object} TicketResponse
+// @Failure 404 {object} APIError
+// @Failure 500 {object} APIError
+// @Router /api/v1/tickets/{ticket_ref} [get]
+func GetTicket(ctx *gin.Context) {
+	// Reject forged references before touching the database
+	ref := ctx.Param("ticket_ref")
+	if !models.VerifyTicketRef(ref) {
+		respondError(ctx, http.StatusNotFound, ErrInvalidTicket)
+		return
+	}
+
+	// Get the database from the context
+	db, ok := ctx.Get(db.DbKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	card, err := models.GetCardByTicket(db.(*gorm.DB), ref)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondError(ctx, http.StatusNotFound, ErrInvalidTicket)
+		return
+	}
+	if err != nil {
+		log.WithField("src", "api.GetTicket").WithError(err).Error("Error getting ticket")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	results, err := models.GetCardResults(db.(*gorm.DB), card.ID)
+	if err != nil {
+		log.WithField("src", "api.GetTicket").WithError(err).Error("Error getting card results")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	// Cards are checked against MaxCardTotal when placed, so can't overflow
+	totalCost, _ := card.TotalCost()
+
+	resp := TicketResponse{
+		TicketRef:    card.TicketRef,
+		Selection:    utils.ToInts(card.Selection),
+		Spots:        card.Spots(),
+		StartGame:    card.StartGame,
+		LastGame:     card.LastGame,
+		PricePerGame: card.PerGame,
+		TotalCost:    totalCost,
+		Games:        make([]CardGameResult, 0, len(results)),
+	}
+
+	for _, result := range results {
+		resp.Won, _ = utils.AddUint64(resp.Won, result.Prize)
+		resp.Games = append(resp.Games, CardGameResult{
+			GameId:  result.GameID,
+			Matches: result.Matches,
+			Prize:   result.Prize,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+type TicketResponse struct {
+	TicketRef    string           `json:"ticket_ref"`
+	Selection    []int            `json:"selection"`
+	Spots        uint8            `json:"spots"`
+	StartGame    uint64           `json:"start_game_num"`
+	LastGame     uint64           `json:"last_game_num"`
+	PricePerGame uint64           `json:"price_per_game"`
+	TotalCost    uint64           `json:"total_cost"`
+	Won          uint64           `json:"won"`
+	Games        []CardGameResult `json:"games"`
+}
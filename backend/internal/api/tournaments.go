@@ -0,0 +1,484 @@
+package api
+
+import (
+	"errors"
+	"keno/internal/db"
+	"keno/internal/engine"
+	"keno/internal/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	MaxTournamentNameLength        = 64
+	MaxTournamentGames      uint64 = 2_000
+	MaxTournamentPrizes            = 100
+)
+
+// Create a Tournament
+// @Summary Create a tournament, admins only
+// @Description Set up a tournament over the games from `first_game_num` up to, but not including, `last_game_num`. Everyone who registers gets `bankroll` to place tournament cards with, and once the last game is settled the entry ranked first is paid the first of `prizes`, second the second and so on.
+// @Tags tournaments
+// @Accept json
+// @Produce json
+// @Param tournament body TournamentRequest true "The tournament"
+// @Success 200 {object} TournamentResponse
+// @Failure 400 {object} APIError
+// @Failure 403 {object} APIError
+// @Failure 500 {object} APIError
+// @Router /api/v1/tournaments [post]
+func CreateTournament(ctx *gin.Context) {
+	req := TournamentRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		log.WithField("src", "api.CreateTournament").Error("Tournament call made with invalid JSON body")
+		respondError(ctx, http.StatusBadRequest, bindError(ErrInvalidTournament, err))
+		return
+	}
+
+	// Get the game engine from the context
+	gameEngine, ok := ctx.Get(engine.EngineKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	if len(req.Name) == 0 || len(req.Name) > MaxTournamentNameLength ||
+		req.FirstGame < gameEngine.(*engine.Engine).GetNextOpenGame() ||
+		req.LastGame <= req.FirstGame || req.LastGame-req.FirstGame > MaxTournamentGames ||
+		req.Bankroll < ValidStakeMin || len(req.Prizes) > MaxTournamentPrizes {
+		log.WithField("src", "api.CreateTournament").Error("Tournament call made with invalid values")
+		respondError(ctx, http.StatusBadRequest, ErrInvalidTournament)
+		return
+	}
+
+	// Get the database from the context
+	db, ok := ctx.Get(db.DbKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	tournament, err := models.CreateTournament(db.(*gorm.DB), models.Tournament{
+		Name:      req.Name,
+		FirstGame: req.FirstGame,
+		LastGame:  req.LastGame,
+		Bankroll:  req.Bankroll,
+		Prizes:    req.Prizes,
+	})
+	if err != nil {
+		log.WithField("src", "api.CreateTournament").WithError(err).Error("Error creating tournament")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tournamentToResponse(*tournament))
+}
+
+// List Tournaments
+// @Summary List the tournaments
+// @Description Get every tournament, latest first.
+// @Tags tournaments
+// @Produce json
+// @Success 200 {array} TournamentResponse
+// @Failure 500 {object} APIError
+// @Router /api/v1/tournaments [get]
+func ListTournaments(ctx *gin.Context) {
+	// Get the database from the context
+	db, ok := ctx.Get(db.DbKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	tournaments, err := models.GetTournaments(db.(*gorm.DB))
+	if err != nil {
+		log.WithField("src", "api.ListTournaments").WithError(err).Error("Error getting tournaments")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	resp := make([]TournamentResponse, 0, len(tournaments))
+	for _, tournament := range tournaments {
+		resp = append(resp, tournamentToResponse(tournament))
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// Tournament Standings
+// @Summary Get the standings of a tournament
+// @Description Get every entry in a tournament ranked by how much their tournament cards have won. Entries on the same score are ranked by who has more bankroll left, then by who registered first. Scores are updated as each game is settled, and once the tournament has finished the ranks are final and include the prizes paid.
+// @Tags tournaments
+// @param tournament_id path int true "Tournament ID"
+// @Produce json
+// @Success 200 {object} StandingsResponse
+// @Failure 400 {object} APIError
+// @Failure 404 {object} APIError
+// @Failure 500 {object} APIError
+// @Router /api/v1/tournaments/{tournament_id}/standings [get]
+func GetStandings(ctx *gin.Context) {
+	tournament, ok := getTournament(ctx)
+	if !ok {
+		return
+	}
+
+	// Get the database from the context
+	db, ok := ctx.Get(db.DbKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	entries, err := models.GetStandings(db.(*gorm.DB), tournament.ID)
+	if err != nil {
+		log.WithField("src", "api.GetStandings").WithError(err).Error("Error getting standings")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	resp := StandingsResponse{
+		Tournament: tournamentToResponse(*tournament),
+		Entries:    make([]StandingResponse, 0, len(entries)),
+	}
+
+	for _, entry := range entries {
+		resp.Entries = append(resp.Entries, entryToResponse(entry))
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// Register for a Tournament
+// @Summary Register for a tournament
+// @Description Enter a tournament and get its bankroll to place tournament cards with. You can register any time before its last game starts.
+// @Tags tournaments
+// @param tournament_id path int true "Tournament ID"
+// @Produce json
+// @Success 200 {object} StandingResponse
+// @Failure 400 {object} APIError
+// @Failure 403 {object} APIError
+// @Failure 404 {object} APIError
+// @Failure 409 {object} APIError
+// @Failure 500 {object} APIError
+// @Router /api/v1/tournaments/{tournament_id}/register [post]
+func RegisterForTournament(ctx *gin.Context) {
+	tournament, ok := getTournament(ctx)
+	if !ok {
+		return
+	}
+
+	// Get the database from the context
+	db, ok := ctx.Get(db.DbKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	// Get the game engine from the context
+	gameEngine, ok := ctx.Get(engine.EngineKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	userId := ctx.GetString(USER_ID_KEY)
+	var entry *models.TournamentEntry
+	err := gameEngine.(*engine.Engine).WithOpenGame(func(gameNum uint64) error {
+		if gameNum >= tournament.LastGame {
+			return ErrTournamentClosed
+		}
+
+		return db.(*gorm.DB).Transaction(func(tx *gorm.DB) (err error) {
+			if _, err := checkExclusion(tx, userId); err != nil {
+				return err
+			}
+
+			entry, err = models.RegisterForTournament(tx, tournament, userId)
+			return err
+		})
+	})
+	if errors.Is(err, models.ErrAlreadyRegistered) {
+		respondError(ctx, http.StatusConflict, ErrAlreadyRegistered)
+		return
+	}
+
+	var apiErr APIError
+	if errors.As(err, &apiErr) {
+		log.WithField("src", "api.RegisterForTournament").WithError(err).Error("Register call refused")
+		respondError(ctx, tournamentErrorStatus(apiErr), apiErr)
+		return
+	}
+	if err != nil {
+		log.WithField("src", "api.RegisterForTournament").WithError(err).Error("Error registering for tournament")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entryToResponse(*entry))
+}
+
+// Place Tournament Picks
+// @Summary Place a tournament card
+// @Description Place a card in a tournament you have registered for, paid for from your tournament bankroll. The card follows the same rules as placing picks, other than the game liability, and has to start and finish within the tournament's games. Tournament cards only win tournament points, which make up your score.
+// @Tags tournaments
+// @Accept json
+// @Produce json
+// @param tournament_id path int true "Tournament ID"
+// @Param Idempotency-Key header string false "Unique key for the request, retries with the same key replay the first response instead of placing another card"
+// @Param picks body PickRequest true "Your picks"
+// @Success 200 {object} PickResponse
+// @Failure 400 {object} APIError
+// @Failure 403 {object} APIError
+// @Failure 404 {object} APIError
+// @Failure 409 {object} APIError
+// @Failure 500 {object} APIError
+// @Router /api/v1/tournaments/{tournament_id}/picks [post]
+func PlaceTournamentPicks(ctx *gin.Context) {
+	tournament, ok := getTournament(ctx)
+	if !ok {
+		return
+	}
+
+	req := PickRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		log.WithField("src", "api.PlaceTournamentPicks").Error("Picks call made with invalid JSON body")
+		respondError(ctx, http.StatusBadRequest, bindError(ErrInvalidPicks, err))
+		return
+	}
+
+	userId := ctx.GetString(USER_ID_KEY)
+
+	// Generate quick picks then validate the picks and stake
+	req.generatePicks(userId)
+	if err := req.validate(); err != nil {
+		log.WithField("src", "api.PlaceTournamentPicks").WithError(err).Error("Picks call made with invalid values")
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	// Get the database from the context
+	db, ok := ctx.Get(db.DbKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	// Get the game engine from the context
+	gameEngine, ok := ctx.Get(engine.EngineKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	var card *models.Card
+	var startTime int64
+	err := gameEngine.(*engine.Engine).WithOpenGame(func(gameNum uint64) error {
+		startTime = gameEngine.(*engine.Engine).GetNextGame().UnixMilli()
+
+		newCard := req.toCard(gameNum, userId)
+		newCard.TournamentID = tournament.ID
+		if newCard.StartGame < tournament.FirstGame || newCard.LastGame > tournament.LastGame {
+			return ErrTournamentClosed
+		}
+
+		return db.(*gorm.DB).Transaction(func(tx *gorm.DB) (err error) {
+			if _, err := checkExclusion(tx, userId); err != nil {
+				return err
+			}
+
+			entry, err := models.GetTournamentEntry(tx, tournament.ID, userId)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotRegistered
+			}
+			if err != nil {
+				return err
+			}
+
+			// Cards are checked against MaxCardTotal so can't overflow
+			cost, _ := newCard.TotalCost()
+			if err := models.SpendBankroll(tx, entry, cost); err != nil {
+				return err
+			}
+
+			card, err = models.SubmitCard(tx, newCard, models.LiabilityCaps{})
+			return err
+		})
+	})
+	if errors.Is(err, models.ErrBankrollSpent) {
+		respondError(ctx, http.StatusBadRequest, ErrBankrollSpent)
+		return
+	}
+
+	var apiErr APIError
+	if errors.As(err, &apiErr) {
+		log.WithField("src", "api.PlaceTournamentPicks").WithError(err).Error("Picks call refused")
+		respondError(ctx, tournamentErrorStatus(apiErr), apiErr)
+		return
+	}
+	if err != nil {
+		log.WithField("src", "api.PlaceTournamentPicks").WithError(err).Error("Error submitting picks")
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return
+	}
+
+	notifyCardPlaced(gameEngine.(*engine.Engine), *card)
+
+	resp := cardToPickResponse(*card)
+	resp.StartTime = startTime
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// getTournament gets the tournament in the URL, otherwise it writes the error
+// response. ok is false if the response has been written.
+func getTournament(ctx *gin.Context) (*models.Tournament, bool) {
+	tournamentId, err := strconv.ParseUint(ctx.Param("tournament_id"), 10, 64)
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, ErrInvalidTournament)
+		return nil, false
+	}
+
+	// Get the database from the context
+	db, ok := ctx.Get(db.DbKey)
+	if !ok {
+		respondError(ctx, http.StatusInternalServerError, ErrInternalError)
+		return nil, false
+	}
+
+	tournament, err := models.GetTournament(db.(*gorm.DB), tournamentId)
+	if err != nil {
+		respondError(ctx, http.StatusNotFound, ErrInvalidTournament)
+		return nil, false
+	}
+
+	return tournament, true
+}
+
+// tournamentErrorStatus returns the HTTP status for an error found while
+// registering for or playing in a tournament.
+func tournamentErrorStatus(err APIError) int {
+	switch {
+	case errors.Is(err, ErrTournamentClosed):
+		return http.StatusConflict
+	case errors.Is(err, ErrNotRegistered):
+		return http.StatusForbidden
+	}
+
+	return errorStatus(err)
+}
+
+// ScoreTournaments returns a settlement hook which updates the scores of every
+// tournament with cards on the game. Once a tournament's last game has been
+// settled, or a later one if it never was, its prizes are paid and everyone
+// who entered is sent a TRN message with how they did.
+func ScoreTournaments(db *gorm.DB, gameEngine *engine.Engine) func(game models.Game, results []models.CardResult) error {
+	return func(game models.Game, results []models.CardResult) error {
+		for _, id := range models.GetGameTournaments(results) {
+			if err := models.UpdateTournamentScores(db, id); err != nil {
+				log.WithFields(log.Fields{
+					"src":        "api.ScoreTournaments",
+					"tournament": id,
+				}).WithError(err).Error("Error updating tournament scores")
+
+				// Finishing the tournament now would rank the wrong scores
+				return err
+			}
+		}
+
+		// Tournaments can finish without any cards on their last game. Any
+		// which should already have finished are caught up too, in case their
+		// last game was never settled.
+		var tournaments []models.Tournament
+		err := db.Where("last_game <= ? AND status = ?", game.ID+1, models.TournamentOpen).Find(&tournaments).Error
+		if err != nil {
+			log.WithField("src", "api.ScoreTournaments").WithError(err).Error("Error getting finished tournaments")
+			return err
+		}
+
+		var failed error
+		for i := range tournaments {
+			standings, err := models.FinishTournament(db, &tournaments[i])
+			if err != nil {
+				log.WithFields(log.Fields{
+					"src":        "api.ScoreTournaments",
+					"tournament": tournaments[i].ID,
+				}).WithError(err).Error("Error finishing tournament")
+				failed = errors.Join(failed, err)
+				continue
+			}
+
+			for _, entry := range standings {
+				gameEngine.NotifyUser(entry.Tenant, entry.User, models.GenerateMessage(models.TournamentMsg{
+					TournamentId: entry.TournamentID,
+					Rank:         entry.Rank,
+					Score:        entry.Score,
+					Prize:        entry.Prize,
+				}))
+			}
+		}
+
+		return failed
+	}
+}
+
+type TournamentRequest struct {
+	Name      string   `json:"name"`
+	FirstGame uint64   `json:"first_game_num"`
+	LastGame  uint64   `json:"last_game_num"`
+	Bankroll  uint64   `json:"bankroll"`
+	Prizes    []uint64 `json:"prizes"`
+}
+
+type TournamentResponse struct {
+	TournamentId uint64   `json:"tournament_id"`
+	Name         string   `json:"name"`
+	FirstGame    uint64   `json:"first_game_num"`
+	LastGame     uint64   `json:"last_game_num"`
+	Bankroll     uint64   `json:"bankroll"`
+	Prizes       []uint64 `json:"prizes"`
+	Status       string   `json:"status"`
+}
+
+type StandingsResponse struct {
+	Tournament TournamentResponse `json:"tournament"`
+	Entries    []StandingResponse `json:"entries"`
+}
+
+type StandingResponse struct {
+	Rank    int    `json:"rank"`
+	User    string `json:"user"`
+	Score   uint64 `json:"score"`
+	Balance uint64 `json:"balance"`
+	Prize   uint64 `json:"prize"`
+}
+
+func tournamentToResponse(tournament models.Tournament) TournamentResponse {
+	prizes := tournament.Prizes
+	if prizes == nil {
+		prizes = []uint64{}
+	}
+
+	return TournamentResponse{
+		TournamentId: tournament.ID,
+		Name:         tournament.Name,
+		FirstGame:    tournament.FirstGame,
+		LastGame:     tournament.LastGame,
+		Bankroll:     tournament.Bankroll,
+		Prizes:       prizes,
+		Status:       tournament.Status,
+	}
+}
+
+func entryToResponse(entry models.TournamentEntry) StandingResponse {
+	return StandingResponse{
+		Rank:    entry.Rank,
+		User:    entry.User,
+		Score:   entry.Score,
+		Balance: entry.Balance,
+		Prize:   entry.Prize,
+	}
+}
@@ -1,12 +1,227 @@
 package api
 
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// APIError is returned whenever a request fails. Code is stable and meant for
+// clients to act on, Message is meant for people. Details point at the fields
+// of the request which were wrong, and RequestId matches the response up with
+// the server's logs.
 type APIError struct {
+	Code      string        `json:"code"`
+	Message   string        `json:"message"`
+	Details   []ErrorDetail `json:"details,omitempty"`
+	RequestId string        `json:"request_id,omitempty"`
+}
+
+// ErrorDetail is a single problem with a field of the request. Field is the
+// path to it in the request, like `picks[3]`, and Code is one of the Detail
+// codes.
+type ErrorDetail struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
 	Message string `json:"message"`
 }
 
+// Codes for the problems an ErrorDetail can describe.
+const (
+	DetailOutOfRange  = "OUT_OF_RANGE"
+	DetailNotAllowed  = "NOT_ALLOWED"
+	DetailWrongCount  = "WRONG_COUNT"
+	DetailTooMany     = "TOO_MANY"
+	DetailDuplicate   = "DUPLICATE"
+	DetailInvalidType = "INVALID_TYPE"
+	DetailInvalidJSON = "INVALID_JSON"
+)
+
+func (e APIError) Error() string {
+	return e.Message
+}
+
+// Is matches errors by their code, so an error which has had details or a
+// request ID added is still the error it was made from.
+func (e APIError) Is(target error) bool {
+	t, ok := target.(APIError)
+	return ok && t.Code == e.Code
+}
+
+// WithDetails returns a copy of the error with the details added.
+func (e APIError) WithDetails(details ...ErrorDetail) APIError {
+	e.Details = append(append([]ErrorDetail{}, e.Details...), details...)
+	return e
+}
+
+// WithFieldPrefix returns a copy of the error with prefix added to the field
+// of each detail, for requests which are nested inside another.
+func (e APIError) WithFieldPrefix(prefix string) APIError {
+	details := make([]ErrorDetail, 0, len(e.Details))
+	for _, detail := range e.Details {
+		detail.Field = prefix + detail.Field
+		details = append(details, detail)
+	}
+
+	e.Details = details
+	return e
+}
+
+// toAPIError returns err as an APIError, anything which isn't one already is
+// treated as an internal error.
+func toAPIError(err error) APIError {
+	var apiErr APIError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	return ErrInternalError
+}
+
+// respondError aborts the request with the error and its request ID, every
+// handler and middleware reports errors through here so they all look the
+// same. Errors which aren't an APIError are sent as ErrInternalError.
+func respondError(ctx *gin.Context, status int, err error) {
+	apiErr := toAPIError(err)
+	apiErr.RequestId = ctx.GetString(REQUEST_ID_KEY)
+
+	log.WithFields(log.Fields{
+		"src":        "api.respondError",
+		"request_id": apiErr.RequestId,
+		"status":     status,
+		"code":       apiErr.Code,
+	}).Info("Request failed")
+
+	ctx.AbortWithStatusJSON(status, apiErr)
+}
+
+// Name the fields in validation errors the way the client sent them, rather
+// than by their Go names.
+func init() {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			for _, tag := range []string{"json", "form"} {
+				if name := strings.Split(field.Tag.Get(tag), ",")[0]; name != "" && name != "-" {
+					return name
+				}
+			}
+			return field.Name
+		})
+	}
+}
+
+// bindError returns apiErr with details of what was wrong with a request
+// body or query which couldn't be bound.
+func bindError(apiErr APIError, err error) APIError {
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	var validationErrs validator.ValidationErrors
+
+	switch {
+	case errors.As(err, &typeErr):
+		return apiErr.WithDetails(ErrorDetail{
+			Field:   typeErr.Field,
+			Code:    DetailInvalidType,
+			Message: fmt.Sprintf("expected %s but got %s", typeErr.Type, typeErr.Value),
+		})
+	case errors.As(err, &syntaxErr):
+		return apiErr.WithDetails(ErrorDetail{
+			Code:    DetailInvalidJSON,
+			Message: fmt.Sprintf("invalid JSON at offset %d", syntaxErr.Offset),
+		})
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return apiErr.WithDetails(ErrorDetail{
+			Code:    DetailInvalidJSON,
+			Message: "JSON ended early",
+		})
+	case errors.As(err, &validationErrs):
+		details := make([]ErrorDetail, 0, len(validationErrs))
+		for _, fieldErr := range validationErrs {
+			details = append(details, ErrorDetail{
+				Field:   fieldErr.Field(),
+				Code:    DetailOutOfRange,
+				Message: fmt.Sprintf("failed the %s=%s rule", fieldErr.Tag(), fieldErr.Param()),
+			})
+		}
+		return apiErr.WithDetails(details...)
+	}
+
+	return apiErr
+}
+
 var (
-	ErrInvalidPicks    = APIError{Message: "Invalid picks"}
-	ErrUnfinishedGames = APIError{Message: "Games haven't finished"}
-	ErrInvalidCard     = APIError{Message: "Invalid Card ID"}
-	ErrInternalError   = APIError{Message: "Internal Error"}
+	ErrInvalidPicks    = APIError{Code: "INVALID_PICKS", Message: "Invalid picks"}
+	ErrUnfinishedGames = APIError{Code: "UNFINISHED_GAMES", Message: "Games haven't finished"}
+	ErrInvalidCard     = APIError{Code: "INVALID_CARD", Message: "Invalid Card ID"}
+	ErrNothingToCancel = APIError{Code: "NOTHING_TO_CANCEL", Message: "No games left to cancel"}
+	ErrInvalidBatch    = APIError{Code: "INVALID_BATCH", Message: "Invalid batch of picks"}
+	ErrInvalidToken    = APIError{Code: "INVALID_TOKEN", Message: "Invalid token"}
+	ErrInvalidTicket   = APIError{Code: "INVALID_TICKET", Message: "Invalid ticket reference"}
+	ErrInvalidGame     = APIError{Code: "INVALID_GAME", Message: "Invalid Game ID"}
+
+	ErrInvalidGamesQuery  = APIError{Code: "INVALID_GAMES_QUERY", Message: "Invalid games query"}
+	ErrInvalidLeaderboard = APIError{Code: "INVALID_LEADERBOARD", Message: "Invalid leaderboard or period"}
+	ErrNotAdmin           = APIError{Code: "NOT_ADMIN", Message: "Only admins can do that"}
+	ErrInvalidGuild       = APIError{Code: "INVALID_GUILD", Message: "Unknown guild, or you aren't a member of it"}
+	ErrInvalidRequest     = APIError{Code: "INVALID_REQUEST", Message: "Invalid request"}
+
+	// Idempotency errors
+	ErrInvalidIdempotencyKey    = APIError{Code: "INVALID_IDEMPOTENCY_KEY", Message: "Invalid Idempotency-Key"}
+	ErrIdempotencyKeyReused     = APIError{Code: "IDEMPOTENCY_KEY_REUSED", Message: "Idempotency-Key was already used for a different request"}
+	ErrIdempotencyKeyInProgress = APIError{Code: "IDEMPOTENCY_KEY_IN_PROGRESS", Message: "A request with this Idempotency-Key is still being handled"}
+
+	// Favourite errors
+	ErrInvalidFavourite  = APIError{Code: "INVALID_FAVOURITE", Message: "Invalid favourite"}
+	ErrTooManyFavourites = APIError{Code: "TOO_MANY_FAVOURITES", Message: "Too many favourites saved"}
+
+	// Subscription errors
+	ErrInvalidSubscription  = APIError{Code: "INVALID_SUBSCRIPTION", Message: "Invalid subscription"}
+	ErrTooManySubscriptions = APIError{Code: "TOO_MANY_SUBSCRIPTIONS", Message: "Too many active subscriptions"}
+	ErrSubscriptionInactive = APIError{Code: "SUBSCRIPTION_INACTIVE", Message: "Subscription has already stopped"}
+
+	// Syndicate errors
+	ErrInvalidSyndicate = APIError{Code: "INVALID_SYNDICATE", Message: "Invalid syndicate"}
+	ErrSyndicateClosed  = APIError{Code: "SYNDICATE_CLOSED", Message: "Syndicate is no longer selling shares"}
+	ErrNotEnoughShares  = APIError{Code: "NOT_ENOUGH_SHARES", Message: "Syndicate doesn't have that many shares left"}
+	ErrSyndicateCard    = APIError{Code: "SYNDICATE_CARD", Message: "Syndicate cards can't be changed"}
+
+	// Tournament errors
+	ErrInvalidTournament = APIError{Code: "INVALID_TOURNAMENT", Message: "Invalid tournament"}
+	ErrTournamentClosed  = APIError{Code: "TOURNAMENT_CLOSED", Message: "Tournament isn't taking part in this game"}
+	ErrAlreadyRegistered = APIError{Code: "ALREADY_REGISTERED", Message: "You have already registered for this tournament"}
+	ErrNotRegistered     = APIError{Code: "NOT_REGISTERED", Message: "You haven't registered for this tournament"}
+	ErrBankrollSpent     = APIError{Code: "BANKROLL_SPENT", Message: "Card costs more than your tournament bankroll has left"}
+	ErrTournamentCard    = APIError{Code: "TOURNAMENT_CARD", Message: "Tournament cards can't be changed"}
+
+	// Challenge errors
+	ErrInvalidChallenge     = APIError{Code: "INVALID_CHALLENGE", Message: "Invalid challenge"}
+	ErrChallengeNotPending  = APIError{Code: "CHALLENGE_NOT_PENDING", Message: "Challenge is no longer pending"}
+	ErrChallengeGameStarted = APIError{Code: "CHALLENGE_GAME_STARTED", Message: "Challenge's game has already started"}
+
+	// Responsible gambling errors
+	ErrInvalidLimits    = APIError{Code: "INVALID_LIMITS", Message: "Invalid limits"}
+	ErrSelfExcluded     = APIError{Code: "SELF_EXCLUDED", Message: "You have excluded yourself from playing"}
+	ErrCoolingOff       = APIError{Code: "COOLING_OFF", Message: "You are taking a break from playing"}
+	ErrStakeLimit       = APIError{Code: "STAKE_LIMIT", Message: "Card costs more than your stake limit"}
+	ErrGuildStakeLimit  = APIError{Code: "GUILD_STAKE_LIMIT", Message: "Card costs more than this guild allows"}
+	ErrDailyLossLimit   = APIError{Code: "DAILY_LOSS_LIMIT", Message: "Card would go over your daily loss limit"}
+	ErrWeeklyLossLimit  = APIError{Code: "WEEKLY_LOSS_LIMIT", Message: "Card would go over your weekly loss limit"}
+	ErrMonthlyLossLimit = APIError{Code: "MONTHLY_LOSS_LIMIT", Message: "Card would go over your monthly loss limit"}
+	ErrInternalError    = APIError{Code: "INTERNAL_ERROR", Message: "Internal Error"}
+
+	// Stake and betting limit errors
+	ErrStakeTooLow       = APIError{Code: "STAKE_TOO_LOW", Message: "Price per game is below the minimum stake"}
+	ErrStakeTooHigh      = APIError{Code: "STAKE_TOO_HIGH", Message: "Price per game is above the maximum stake"}
+	ErrInvalidStake      = APIError{Code: "INVALID_STAKE", Message: "Price per game isn't an allowed denomination"}
+	ErrCardTotalTooHigh  = APIError{Code: "CARD_TOTAL_TOO_HIGH", Message: "Total cost of the card is above the maximum"}
+	ErrLiabilityExceeded = APIError{Code: "LIABILITY_EXCEEDED", Message: "Game has reached its betting limit"}
 )
@@ -10,16 +10,72 @@ import (
 const DbKey = "db"
 
 func SetupDatabase(file string) (*gorm.DB, error) {
-	db, err := gorm.Open(sqlite.Open(file), &gorm.Config{})
+	// Transactions take the write lock when they begin, and wait their turn
+	// for it, so concurrent writers queue up rather than failing when one of
+	// them tries to upgrade a read
+	db, err := gorm.Open(sqlite.Open(file+"?_pragma=busy_timeout(5000)&_txlock=immediate"), &gorm.Config{})
 	if err != nil {
 		return nil, err
 	}
 
+	// Let sessions be scoped to a single tenant
+	if err := models.RegisterTenantScope(db); err != nil {
+		return nil, err
+	}
+
+	// Games from before settlement was added were paid out without it, so
+	// they're marked settled rather than being settled all over again
+	settleExisting := db.Migrator().HasTable(&models.Game{}) &&
+		!db.Migrator().HasColumn(&models.Game{}, "Settled")
+
 	// Migrate the schema
-	err = db.AutoMigrate(&models.Game{}, &models.Card{})
+	err = db.AutoMigrate(
+		&models.Game{},
+		&models.Card{},
+		&models.GameLiability{},
+		&models.SettledHook{},
+		&models.Secret{},
+		&models.Cancellation{},
+		&models.Favourite{},
+		&models.History{},
+		&models.Subscription{},
+		&models.Limits{},
+		&models.CardResult{},
+		&models.IdempotencyRecord{},
+		&models.Syndicate{},
+		&models.SyndicateShare{},
+		&models.SyndicatePayout{},
+		&models.Challenge{},
+		&models.LeaderboardEntry{},
+		&models.LeaderboardTotal{},
+		&models.LeaderboardGame{},
+		&models.UserAchievement{},
+		&models.Tournament{},
+		&models.TournamentEntry{},
+	)
 	if err != nil {
 		return nil, err
 	}
 
+	if settleExisting {
+		if err := db.Model(&models.Game{}).Where("1 = 1").Update("settled", true).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	// These indexes have been replaced, the unique ones now include the
+	// tenant and ticket references have become unique
+	for model, index := range map[interface{}]string{
+		&models.IdempotencyRecord{}: "idx_idempotency_key",
+		&models.UserAchievement{}:   "idx_user_achievement",
+		&models.Card{}:              "idx_cards_ticket_ref",
+	} {
+		if db.Migrator().HasIndex(model, index) {
+			if err := db.Migrator().DropIndex(model, index); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	return db, nil
 }
@@ -16,11 +16,21 @@ const (
 	PlayTime = 90 * time.Second
 	WaitTime = 90 * time.Second
 
-	NumberPicks    = 20
+	NumberPicks    = models.DrawSize
 	NumberRangeMin = 1
 	NumberRangeMax = 80
 
 	EngineKey = "engine"
+
+	// ReplaySize is how many of the latest game messages are kept for
+	// listeners which reconnect, enough for a few games
+	ReplaySize = 64
+
+	// Betting is open while the engine is waiting for the next game to start
+	// and closes as soon as the draw begins, cards placed while a game is
+	// drawing go onto the game after it.
+	PhaseBetting = "BETTING"
+	PhaseDrawing = "DRAWING"
 )
 
 type Engine struct {
@@ -28,10 +38,26 @@ type Engine struct {
 	nextGameTime    time.Time
 	curGamStartTime time.Time
 	curGame         models.Game
+	drawing         bool
 	mu              sync.RWMutex
 	db              *gorm.DB
 
-	listeners []chan models.Message
+	// betMu is held for reading while a bet is being placed and for writing
+	// while the betting window closes, so a bet can never land on a game
+	// after its draw has started.
+	betMu sync.RWMutex
+
+	// seq is the Seq of the latest game message, recent holds the last
+	// ReplaySize of them for listeners which reconnect. Numbering starts from
+	// the time the engine was set up, so a restart never reuses a Seq.
+	seq    uint64
+	recent []models.Message
+
+	listeners     []chan models.Message
+	userListeners map[userKey][]chan models.Message
+	startHooks    []func(gameNum uint64)
+	closedHooks   []func(gameNum uint64)
+	completeHooks []func(game models.Game)
 }
 
 func SetupEngine(db *gorm.DB) *Engine {
@@ -50,7 +76,14 @@ func SetupEngine(db *gorm.DB) *Engine {
 		curGame:         models.Game{},
 		db:              db,
 		mu:              sync.RWMutex{},
+		betMu:           sync.RWMutex{},
+		seq:             uint64(time.Now().UnixMilli()),
+		recent:          make([]models.Message, 0, ReplaySize),
 		listeners:       make([]chan models.Message, 0),
+		userListeners:   make(map[userKey][]chan models.Message),
+		startHooks:      make([]func(gameNum uint64), 0),
+		closedHooks:     make([]func(gameNum uint64), 0),
+		completeHooks:   make([]func(game models.Game), 0),
 	}
 }
 
@@ -88,6 +121,57 @@ func (engine *Engine) GetCurGameStart() time.Time {
 	return engine.curGamStartTime
 }
 
+// GetPhase is a method that returns whether the engine is currently taking
+// bets or drawing a game, as either PhaseBetting or PhaseDrawing.
+func (engine *Engine) GetPhase() string {
+	engine.mu.RLock()
+	defer engine.mu.RUnlock()
+
+	if engine.drawing {
+		return PhaseDrawing
+	}
+
+	return PhaseBetting
+}
+
+// GetDrawingGame returns the number of the game being drawn, or 0 while
+// betting is open.
+func (engine *Engine) GetDrawingGame() uint64 {
+	engine.mu.RLock()
+	defer engine.mu.RUnlock()
+
+	if engine.drawing {
+		return engine.gameNumber
+	}
+
+	return 0
+}
+
+// GetNextOpenGame is a method that returns the number of the next game which
+// is still open for betting. While a game is being drawn this is the game
+// after it, otherwise it is the current game number.
+func (engine *Engine) GetNextOpenGame() uint64 {
+	engine.mu.RLock()
+	defer engine.mu.RUnlock()
+
+	if engine.drawing {
+		return engine.gameNumber + 1
+	}
+
+	return engine.gameNumber
+}
+
+// WithOpenGame calls fn with the number of the next game open for betting and
+// keeps the betting window from closing until fn returns. Anything which
+// places or changes a bet should do so inside fn, otherwise the draw could
+// start between reading the game number and committing the bet.
+func (engine *Engine) WithOpenGame(fn func(gameNum uint64) error) error {
+	engine.betMu.RLock()
+	defer engine.betMu.RUnlock()
+
+	return fn(engine.GetNextOpenGame())
+}
+
 // ==================
 // Notification Logic
 // ==================
@@ -102,13 +186,72 @@ func (engine *Engine) AddListener(listener chan models.Message) {
 	defer engine.mu.Unlock()
 	engine.listeners = append(engine.listeners, listener)
 
-	// Generate a Current Game Message
+	engine.sendCurrentGame(listener)
+}
+
+// AddListenerAfter adds a listener which has already seen the game messages
+// up to lastSeq, such as a client reconnecting. It is sent the messages it
+// missed if they are still in the replay buffer, otherwise it is sent the
+// current game message like AddListener. The listener's channel needs room
+// for ReplaySize messages.
+func (engine *Engine) AddListenerAfter(listener chan models.Message, lastSeq uint64) {
+	engine.mu.Lock()
+	defer engine.mu.Unlock()
+	engine.listeners = append(engine.listeners, listener)
+
+	// Check the replay buffer still has every message after lastSeq
+	if lastSeq > engine.seq || lastSeq < engine.seq-uint64(len(engine.recent)) {
+		engine.sendCurrentGame(listener)
+		return
+	}
+
+	for _, msg := range engine.recent[len(engine.recent)-int(engine.seq-lastSeq):] {
+		select {
+		case listener <- msg:
+		default:
+			log.WithField("src", "engine.AddListenerAfter").Error("Listener channel full")
+		}
+	}
+}
+
+// sendCurrentGame sends the current game message to the listener, engine.mu
+// has to be held while calling it.
+func (engine *Engine) sendCurrentGame(listener chan models.Message) {
+	msg := models.GenerateMessage(engine.currentGame())
+	msg.Seq = engine.seq
+
+	select {
+	case listener <- msg:
+	default:
+		log.WithField("src", "engine.AddListener").Error("Listener channel full")
+	}
+}
+
+// GetCurrentGame is a method that returns a snapshot of the current game, the
+// same one sent to new listeners. This method is useful for clients which
+// poll for the game state instead of listening for it.
+func (engine *Engine) GetCurrentGame() models.CurrentGameMsg {
+	engine.mu.RLock()
+	defer engine.mu.RUnlock()
+
+	return engine.currentGame()
+}
+
+// currentGame returns the state of the current game, engine.mu has to be held
+// while calling it.
+func (engine *Engine) currentGame() models.CurrentGameMsg {
 	curGame := models.CurrentGameMsg{
 		GameId:               engine.curGame.ID,
+		Phase:                PhaseBetting,
 		NextGameTime:         engine.nextGameTime.UnixMilli(),
 		CurrentGameStartTime: engine.curGamStartTime.UnixMilli(),
 		CurrentGameEndTime:   engine.curGamStartTime.Add(PlayTime).UnixMilli(),
 		Picks:                make([]int, 0),
+		ServerTime:           time.Now().UnixMilli(),
+	}
+
+	if engine.drawing {
+		curGame.Phase = PhaseDrawing
 	}
 
 	// Converting picks to ints
@@ -116,12 +259,7 @@ func (engine *Engine) AddListener(listener chan models.Message) {
 		curGame.Picks = append(curGame.Picks, int(pick))
 	}
 
-	// Send current game
-	select {
-	case listener <- models.GenerateMessage(curGame):
-	default:
-		log.WithField("src", "engine.AddListener").Error("Listener channel full")
-	}
+	return curGame
 }
 
 // RemoveListener is a method that removes an existing listener from the engine.
@@ -142,8 +280,16 @@ func (engine *Engine) RemoveListener(listener chan models.Message) {
 // This method is useful when you want to notify all the listeners about a new
 // game message. This method is called whenever the game state changes.
 func (engine *Engine) NotifyListeners(game models.Message) {
-	engine.mu.RLock()
-	defer engine.mu.RUnlock()
+	engine.mu.Lock()
+	defer engine.mu.Unlock()
+
+	// Number the message and keep it for listeners which reconnect
+	engine.seq++
+	game.Seq = engine.seq
+	engine.recent = append(engine.recent, game)
+	if len(engine.recent) > ReplaySize {
+		engine.recent = engine.recent[1:]
+	}
 
 	for _, listener := range engine.listeners {
 		select {
@@ -154,6 +300,147 @@ func (engine *Engine) NotifyListeners(game models.Message) {
 	}
 }
 
+// userKey identifies a user within a tenant, the same Discord user is kept
+// apart in every tenant they play in.
+type userKey struct {
+	tenant string
+	user   string
+}
+
+// AddUserListener adds a listener for messages meant only for the given user
+// in the tenant, such as their wins. It doesn't get game updates, the same
+// channel should also be added with AddListener for those.
+func (engine *Engine) AddUserListener(tenant, user string, listener chan models.Message) {
+	engine.mu.Lock()
+	defer engine.mu.Unlock()
+
+	key := userKey{tenant, user}
+	engine.userListeners[key] = append(engine.userListeners[key], listener)
+}
+
+// RemoveUserListener removes a listener added with AddUserListener.
+func (engine *Engine) RemoveUserListener(tenant, user string, listener chan models.Message) {
+	engine.mu.Lock()
+	defer engine.mu.Unlock()
+
+	key := userKey{tenant, user}
+	listeners := engine.userListeners[key]
+	for i, l := range listeners {
+		if l == listener {
+			listeners = append(listeners[:i], listeners[i+1:]...)
+			break
+		}
+	}
+
+	if len(listeners) == 0 {
+		delete(engine.userListeners, key)
+		return
+	}
+
+	engine.userListeners[key] = listeners
+}
+
+// NotifyUser sends the message to every listener the user has connected in
+// the tenant.
+func (engine *Engine) NotifyUser(tenant, user string, msg models.Message) {
+	engine.mu.RLock()
+	defer engine.mu.RUnlock()
+
+	for _, listener := range engine.userListeners[userKey{tenant, user}] {
+		select {
+		case listener <- msg:
+		default:
+			log.WithField("src", "engine.NotifyUser").Error("Listener channel full")
+		}
+	}
+}
+
+// NotifyTenant sends the message to every user listener connected in the
+// tenant, for news which shouldn't reach anyone outside of it.
+func (engine *Engine) NotifyTenant(tenant string, msg models.Message) {
+	engine.mu.RLock()
+	defer engine.mu.RUnlock()
+
+	for key, listeners := range engine.userListeners {
+		if key.tenant != tenant {
+			continue
+		}
+
+		for _, listener := range listeners {
+			select {
+			case listener <- msg:
+			default:
+				log.WithField("src", "engine.NotifyTenant").Error("Listener channel full")
+			}
+		}
+	}
+}
+
+// ==================
+//       Hooks
+// ==================
+
+// AddGameStartHook registers fn to be called with the game number as each game
+// is about to start, just before betting on it closes. Hooks run on the game
+// loop, so the game won't start until they return.
+func (engine *Engine) AddGameStartHook(fn func(gameNum uint64)) {
+	engine.mu.Lock()
+	defer engine.mu.Unlock()
+
+	engine.startHooks = append(engine.startHooks, fn)
+}
+
+func (engine *Engine) runGameStartHooks(gameNum uint64) {
+	engine.mu.RLock()
+	hooks := append([]func(gameNum uint64){}, engine.startHooks...)
+	engine.mu.RUnlock()
+
+	for _, hook := range hooks {
+		hook(gameNum)
+	}
+}
+
+// AddBettingClosedHook registers fn to be called with the game number as
+// betting on each game closes. Hooks run while no bets can be placed, so
+// nothing can change the game's bets between a hook running and the draw
+// starting. They hold up every bet until they return, keep them quick.
+func (engine *Engine) AddBettingClosedHook(fn func(gameNum uint64)) {
+	engine.mu.Lock()
+	defer engine.mu.Unlock()
+
+	engine.closedHooks = append(engine.closedHooks, fn)
+}
+
+func (engine *Engine) runBettingClosedHooks(gameNum uint64) {
+	engine.mu.RLock()
+	hooks := append([]func(gameNum uint64){}, engine.closedHooks...)
+	engine.mu.RUnlock()
+
+	for _, hook := range hooks {
+		hook(gameNum)
+	}
+}
+
+// AddGameCompleteHook registers fn to be called with each game once all of its
+// picks have been drawn. Hooks run on the game loop after betting has reopened,
+// anything slow should be handed off so the next game isn't held up.
+func (engine *Engine) AddGameCompleteHook(fn func(game models.Game)) {
+	engine.mu.Lock()
+	defer engine.mu.Unlock()
+
+	engine.completeHooks = append(engine.completeHooks, fn)
+}
+
+func (engine *Engine) runGameCompleteHooks(game models.Game) {
+	engine.mu.RLock()
+	hooks := append([]func(game models.Game){}, engine.completeHooks...)
+	engine.mu.RUnlock()
+
+	for _, hook := range hooks {
+		hook(game)
+	}
+}
+
 // ==================
 //     Game Logic
 // ==================
@@ -171,11 +458,14 @@ func (engine *Engine) StartLoop() {
 			time.Sleep(engine.calculatePickSleepDuration(i))
 		}
 
-		// Increment Game Number
+		// Increment Game Number and reopen betting
 		engine.mu.Lock()
 		engine.gameNumber++
+		engine.drawing = false
 		engine.mu.Unlock()
 
+		engine.runGameCompleteHooks(*game)
+
 		// Sleep till next game
 		time.Sleep(time.Until(engine.nextGameTime))
 
@@ -188,17 +478,28 @@ func (engine *Engine) StartLoop() {
 }
 
 func (engine *Engine) initialiseGame() *models.Game {
-	// Set the game times for the new game
+	// Give the hooks a chance to place bets before betting closes
+	engine.runGameStartHooks(engine.gameNumber)
+
+	// Close betting and set the game times for the new game. Waiting on
+	// betMu lets any bets which are part way through being placed finish
+	// before the draw starts.
+	engine.betMu.Lock()
+	engine.runBettingClosedHooks(engine.gameNumber)
 	engine.mu.Lock()
+	engine.drawing = true
 	engine.curGamStartTime = time.Now()
 	engine.nextGameTime = engine.curGamStartTime.Add(PlayTime + WaitTime)
 	engine.mu.Unlock()
+	engine.betMu.Unlock()
 
 	// Start new Game
 
 	game := &models.Game{
-		ID:    engine.gameNumber,
-		Picks: []uint8{},
+		ID:        engine.gameNumber,
+		Picks:     []uint8{},
+		StartTime: engine.curGamStartTime,
+		EndTime:   engine.curGamStartTime.Add(PlayTime),
 	}
 
 	// Commit the Game to storage and notify listeners to clear state
@@ -218,14 +519,8 @@ func (engine *Engine) initialiseGame() *models.Game {
 }
 
 func (engine *Engine) generatePick(game *models.Game, picks *map[int]bool, i int) {
-	seed := uint64(time.Now().UnixNano()) + rand.Uint64()
-	r := rand.New(rand.NewSource(int64(seed)))
-
 	// Pick a number that hasn't been picked
-	pick := 0
-	for (*picks)[pick] || pick == 0 {
-		pick = r.Int()%(NumberRangeMax-NumberRangeMin+1) + NumberRangeMin
-	}
+	pick := drawNumber(*picks)
 
 	// Add pick to map
 	(*picks)[pick] = true
@@ -241,6 +536,39 @@ func (engine *Engine) generatePick(game *models.Game, picks *map[int]bool, i int
 	engine.mu.Unlock()
 }
 
+// drawNumber returns a random number in the draw range which isn't already
+// in picks. It is the source of randomness for both the draw and quick picks.
+func drawNumber(picks map[int]bool) int {
+	seed := uint64(time.Now().UnixNano()) + rand.Uint64()
+	r := rand.New(rand.NewSource(int64(seed)))
+
+	pick := 0
+	for picks[pick] || pick == 0 {
+		pick = r.Int()%(NumberRangeMax-NumberRangeMin+1) + NumberRangeMin
+	}
+
+	return pick
+}
+
+// QuickPick returns n different random numbers from the draw range, using the
+// same randomness as the draw itself. It returns nil if n is more numbers than
+// the range holds.
+func QuickPick(n int) []uint8 {
+	if n < 0 || n > NumberRangeMax-NumberRangeMin+1 {
+		return nil
+	}
+
+	picks := map[int]bool{}
+	numbers := make([]uint8, 0, n)
+	for len(numbers) < n {
+		pick := drawNumber(picks)
+		picks[pick] = true
+		numbers = append(numbers, uint8(pick))
+	}
+
+	return numbers
+}
+
 func (engine *Engine) calculatePickSleepDuration(i int) time.Duration {
 	gameEndTime := engine.curGamStartTime.Add(PlayTime)
 	return time.Until(gameEndTime) / time.Duration(NumberPicks-i)
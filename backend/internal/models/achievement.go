@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UserAchievement is an achievement a user has unlocked, along with the card
+// and game which unlocked it. Each achievement can only be unlocked once.
+type UserAchievement struct {
+	ID        uint64 `gorm:"primarykey"`
+	CreatedAt time.Time
+
+	AchievementID string `json:"achievement_id" gorm:"uniqueIndex:idx_tenant_user_achievement"`
+	CardID        uint64 `json:"card_id"`
+	GameID        uint64 `json:"game_id"`
+
+	Tenant string `json:"-" gorm:"uniqueIndex:idx_tenant_user_achievement;default:''"`
+	User   string `json:"user" gorm:"uniqueIndex:idx_tenant_user_achievement;index"`
+}
+
+// UnlockAchievement records that the user has unlocked the achievement.
+// unlocked is false if they already had it.
+func UnlockAchievement(db *gorm.DB, achievement UserAchievement) (bool, error) {
+	achievement.ID = 0
+	achievement.CreatedAt = time.Now()
+
+	tx := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&achievement)
+	if tx.Error != nil {
+		return false, tx.Error
+	}
+
+	return tx.RowsAffected > 0, nil
+}
+
+// GetUserAchievements returns every achievement the user has unlocked, in the
+// order they were unlocked.
+func GetUserAchievements(db *gorm.DB, user string) ([]UserAchievement, error) {
+	var achievements []UserAchievement
+	err := db.Where("user = ?", user).Order("id").Find(&achievements).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return achievements, nil
+}
+
+// CountGamesPlayed returns how many games the user has had settled on their
+// own cards, not counting tournament cards or games which were abandoned.
+func CountGamesPlayed(db *gorm.DB, user string) (int64, error) {
+	var count int64
+	err := db.Model(&CardResult{}).Where("user = ? AND syndicate_id = 0 AND tournament_id = 0 AND refund = 0", user).Count(&count).Error
+	return count, err
+}
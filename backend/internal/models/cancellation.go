@@ -0,0 +1,118 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrNothingToCancel is returned when every game on a card has already
+// started, so there is nothing left which can be cancelled or refunded.
+var ErrNothingToCancel = errors.New("no games left to cancel")
+
+// ErrRefundOverflow is returned when the refund for a cancellation is too
+// large to represent.
+var ErrRefundOverflow = errors.New("refund overflowed")
+
+// Cancellation records the games which were removed from a card before they
+// started and the stake that was refunded for them. The card's LastGame is
+// moved back to FirstGame, so FirstGame to LastGame is the range of games the
+// card originally covered but will no longer be settled against.
+type Cancellation struct {
+	ID        uint64 `gorm:"primarykey"`
+	CreatedAt time.Time
+
+	CardID    uint64 `json:"card_id" gorm:"index"`
+	FirstGame uint64 `json:"first_game_num"`
+	LastGame  uint64 `json:"last_game_num"`
+	Refund    uint64 `json:"refund"`
+
+	Tenant string `json:"-" gorm:"index;default:''"`
+	User   string `json:"user"`
+}
+
+// CancelCard cancels every game on the card from firstGame onwards and
+// records the refund for them. Games before firstGame, or before the card's
+// own StartGame, are kept. The card is updated in place so the caller sees
+// the new LastGame.
+func CancelCard(db *gorm.DB, card *Card, firstGame uint64) (*Cancellation, error) {
+	if firstGame < card.StartGame {
+		firstGame = card.StartGame
+	}
+
+	if firstGame >= card.LastGame {
+		return nil, ErrNothingToCancel
+	}
+
+	// Refund the stake for every cancelled game
+	cancelled := *card
+	cancelled.StartGame = firstGame
+	refund, ok := cancelled.TotalCost()
+	if !ok {
+		return nil, ErrRefundOverflow
+	}
+
+	cancellation := &Cancellation{
+		CreatedAt: time.Now(),
+		CardID:    card.ID,
+		FirstGame: firstGame,
+		LastGame:  card.LastGame,
+		Refund:    refund,
+		Tenant:    card.Tenant,
+		User:      card.User,
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Only shorten the card if nobody else has changed it since it was
+		// read, otherwise the same games could be refunded twice.
+		res := tx.Model(&Card{}).
+			Where("id = ? AND last_game = ?", card.ID, card.LastGame).
+			Update("last_game", firstGame)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrNothingToCancel
+		}
+
+		if err := tx.Create(cancellation).Error; err != nil {
+			return err
+		}
+
+		if err := addLiability(tx, *card, firstGame, card.LastGame, -1, LiabilityCaps{}); err != nil {
+			return err
+		}
+
+		return LogHistory(tx, History{
+			Event:  HistoryCardCancelled,
+			CardID: card.ID,
+			GameID: firstGame,
+			Amount: refund,
+			Tenant: card.Tenant,
+			User:   card.User,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	card.LastGame = firstGame
+	return cancellation, nil
+}
+
+// GetOriginalLastGame returns the LastGame the card was placed with, before
+// any of its games were cancelled.
+func GetOriginalLastGame(db *gorm.DB, card *Card) (uint64, error) {
+	var cancellations []Cancellation
+	err := db.Where("card_id = ?", card.ID).Order("id").Limit(1).Find(&cancellations).Error
+	if err != nil {
+		return 0, err
+	}
+
+	if len(cancellations) == 0 {
+		return card.LastGame, nil
+	}
+
+	return cancellations[0].LastGame, nil
+}
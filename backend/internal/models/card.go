@@ -1,6 +1,8 @@
 package models
 
 import (
+	"keno/internal/utils"
+	"math"
 	"sort"
 	"time"
 
@@ -13,12 +15,36 @@ type Card struct {
 	ID        uint64 `gorm:"primarykey"`
 	CreatedAt time.Time
 
+	// TicketRef is the signed reference the card can be shared and looked up
+	// by without giving away its ID
+	TicketRef string `json:"ticket_ref" gorm:"uniqueIndex:idx_card_ticket_ref"`
+
 	Selection []uint8 `json:"selection"`
-	StartGame uint64  `json:"start_game_num"`
-	LastGame  uint64  `json:"last_game_num"`
+	StartGame uint64  `json:"start_game_num" gorm:"index:idx_card_games"`
+	LastGame  uint64  `json:"last_game_num" gorm:"index:idx_card_games"`
 	PerGame   uint64  `json:"per_game"`
 
-	User string `json:"user"`
+	// SystemSpots is only set on system cards, which play every SystemSpots
+	// sized combination of the Selection each game. PerGame is charged for
+	// every combination.
+	SystemSpots uint8 `json:"system_spots"`
+
+	// QuickPick is set when the selection was generated by the server
+	QuickPick bool `json:"quick_pick"`
+
+	// SubscriptionID is set on cards placed by a subscription
+	SubscriptionID uint64 `json:"subscription_id,omitempty" gorm:"index"`
+
+	// SyndicateID is set on cards bought by a syndicate, their winnings are
+	// paid out to the members rather than User
+	SyndicateID uint64 `json:"syndicate_id,omitempty" gorm:"index;default:0"`
+
+	// TournamentID is set on tournament cards, which are paid for from the
+	// tournament bankroll and only win tournament points
+	TournamentID uint64 `json:"tournament_id,omitempty" gorm:"index;default:0"`
+
+	Tenant string `json:"-" gorm:"index;default:''"`
+	User   string `json:"user"`
 }
 
 func (c Card) CheckCard(db *gorm.DB) uint64 {
@@ -33,13 +59,138 @@ func (c Card) CheckCard(db *gorm.DB) uint64 {
 			continue
 		}
 
-		// Check the game
+		// Check the game, games which were abandoned give back their stake
+		var prize uint64
+		var ok bool
+		if game.Finished() {
+			prize, ok = c.Prize(game)
+		} else {
+			prize, ok = c.CostPerGame()
+		}
+		if ok {
+			amount, ok = utils.AddUint64(amount, prize)
+		}
+		if !ok {
+			log.WithField("card", c.ID).Error("Card winnings overflowed")
+			return math.MaxUint64
+		}
+	}
+
+	return amount
+}
+
+// Prize returns how much the card won on the game. System cards check every
+// combination in the selection against the game and add up their prizes. ok
+// is false if the amount is too large to represent.
+func (c Card) Prize(game *Game) (uint64, bool) {
+	if c.SystemSpots == 0 {
 		matches := game.CheckGame(c.Selection)
-		amount += winMatrix(uint8(len(c.Selection)), matches) * c.PerGame
+		return utils.MulUint64(winMatrix(c.Spots(), matches), c.PerGame)
+	}
+
+	total, ok := uint64(0), true
+	utils.ForEachCombination(c.Selection, int(c.SystemSpots), func(combo []uint8) {
+		matches := game.CheckGame(combo)
+		prize, mulOk := utils.MulUint64(winMatrix(c.SystemSpots, matches), c.PerGame)
+
+		var addOk bool
+		total, addOk = utils.AddUint64(total, prize)
+		ok = ok && mulOk && addOk
+	})
+
+	return total, ok
+}
 
+// Result returns how the card did on the game, ok is false if its prize is too
+// large to represent. If the game was abandoned the result has no matches or
+// prize, and refunds the stake instead.
+func (c Card) Result(game *Game) (CardResult, bool) {
+	stake, ok := c.CostPerGame()
+	if !ok {
+		return CardResult{}, false
 	}
 
-	return amount
+	if !game.Finished() {
+		return CardResult{
+			CreatedAt:    time.Now(),
+			CardID:       c.ID,
+			SyndicateID:  c.SyndicateID,
+			TournamentID: c.TournamentID,
+			GameID:       game.ID,
+			Spots:        c.Spots(),
+			Stake:        stake,
+			Refund:       stake,
+			Tenant:       c.Tenant,
+			User:         c.User,
+		}, true
+	}
+
+	prize, ok := c.Prize(game)
+	if !ok {
+		return CardResult{}, false
+	}
+
+	return CardResult{
+		CreatedAt:    time.Now(),
+		CardID:       c.ID,
+		SyndicateID:  c.SyndicateID,
+		TournamentID: c.TournamentID,
+		GameID:       game.ID,
+		Spots:        c.Spots(),
+		Matches:      game.CheckGame(c.Selection),
+		Stake:        stake,
+		Prize:        prize,
+		Tenant:       c.Tenant,
+		User:         c.User,
+	}, true
+}
+
+// Spots returns how many numbers are played in each selection on the card,
+// for system cards this is the size of each combination.
+func (c Card) Spots() uint8 {
+	if c.SystemSpots > 0 {
+		return c.SystemSpots
+	}
+
+	return uint8(len(c.Selection))
+}
+
+// Combinations returns how many selections the card plays each game, which is
+// always 1 for standard cards.
+func (c Card) Combinations() uint64 {
+	if c.SystemSpots == 0 {
+		return 1
+	}
+
+	return utils.Combinations(len(c.Selection), int(c.SystemSpots))
+}
+
+// CostPerGame returns the stake for a single game, PerGame for every
+// combination played. ok is false if it is too large to represent.
+func (c Card) CostPerGame() (uint64, bool) {
+	return utils.MulUint64(c.PerGame, c.Combinations())
+}
+
+// TotalCost returns the stake for every game on the card. ok is false if it
+// is too large to represent.
+func (c Card) TotalCost() (uint64, bool) {
+	cost, ok := c.CostPerGame()
+	if !ok {
+		return 0, false
+	}
+
+	return utils.MulUint64(cost, c.LastGame-c.StartGame)
+}
+
+// MaxPayout returns the most the card can win on a single game. ok is false if
+// the amount is too large to represent.
+func (c Card) MaxPayout() (uint64, bool) {
+	payout, ok := utils.MulUint64(maxWin(c.Spots()), c.PerGame)
+	if !ok {
+		return 0, false
+	}
+
+	return utils.MulUint64(payout, c.Combinations())
 }
 
 func GetCard(db *gorm.DB, id uint64) (*Card, error) {
@@ -52,24 +203,37 @@ func GetCard(db *gorm.DB, id uint64) (*Card, error) {
 	return &card, nil
 }
 
-func SubmitCard(db *gorm.DB, selection []uint8, startGame uint64, numOfGames uint8, pricePerGame uint64, user string) (*Card, error) {
+// SubmitCard commits a new card to the database. The card should have its
+// selection, games, stake and user set, everything else is filled in here. It
+// returns ErrLiabilityExceeded if the card would push one of its games over
+// the caps.
+func SubmitCard(db *gorm.DB, card Card, caps LiabilityCaps) (*Card, error) {
 	// Sort selection
+	selection := card.Selection
 	sort.Slice(selection, func(i, j int) bool { return selection[i] < selection[j] })
 
 	// Setup the Card
-	newCard := &Card{
-		CreatedAt: time.Now(),
-		Selection: selection,
-		StartGame: startGame,
-		LastGame:  startGame + uint64(numOfGames),
-		PerGame:   uint64(pricePerGame),
-		User:      user,
+	newCard := &card
+	newCard.ID = 0
+	newCard.CreatedAt = time.Now()
+
+	ref, err := NewTicketRef()
+	if err != nil {
+		return nil, err
 	}
+	newCard.TicketRef = ref
+
+	// Create the card in the database, counting it towards its games'
+	// liability
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(newCard).Error; err != nil {
+			return err
+		}
 
-	// Create the card in the database
-	tx := db.Create(newCard)
-	if tx.Error != nil {
-		return nil, tx.Error
+		return addLiability(tx, *newCard, newCard.StartGame, newCard.LastGame, 1, caps)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return newCard, nil
@@ -0,0 +1,285 @@
+package models
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Challenge statuses. A challenge is pending until the opponent accepts it,
+// and is locked once the draw of its game starts. Challenges which haven't been
+// accepted by then expire.
+const (
+	ChallengePending   = "PENDING"
+	ChallengeAccepted  = "ACCEPTED"
+	ChallengeLocked    = "LOCKED"
+	ChallengeResolved  = "RESOLVED"
+	ChallengeExpired   = "EXPIRED"
+	ChallengeCancelled = "CANCELLED"
+)
+
+// Challenge rules, which decide who wins.
+const (
+	// ChallengeRuleMatches is won by whoever matches more numbers
+	ChallengeRuleMatches = "MATCHES"
+	// ChallengeRulePrize is won by whoever's selection would win the bigger
+	// prize as a card
+	ChallengeRulePrize = "PRIZE"
+)
+
+// ErrChallengeNotPending is returned when accepting or cancelling a challenge
+// which isn't pending any more.
+var ErrChallengeNotPending = errors.New("challenge is not pending")
+
+// Challenge is a head-to-head bet between two users on the same game. Both put
+// up Stake and submit a selection of the same size, whoever does better under
+// Rule takes the pot of both stakes. If they draw, each gets their stake back
+// and Winner is left empty.
+type Challenge struct {
+	ID        uint64 `gorm:"primarykey"`
+	CreatedAt time.Time
+
+	GameID uint64 `json:"game_id" gorm:"index"`
+	Stake  uint64 `json:"stake"`
+	Rule   string `json:"rule"`
+	Status string `json:"status" gorm:"index"`
+
+	ChallengerPicks   []uint8 `json:"challenger_picks"`
+	OpponentPicks     []uint8 `json:"opponent_picks"`
+	ChallengerMatches uint8   `json:"challenger_matches"`
+	OpponentMatches   uint8   `json:"opponent_matches"`
+	Winner            string  `json:"winner"`
+
+	// AcceptedAt and ResolvedAt are set as the challenge moves through its
+	// statuses
+	AcceptedAt time.Time `json:"accepted_at"`
+	ResolvedAt time.Time `json:"resolved_at"`
+
+	Tenant     string `json:"-" gorm:"index;default:''"`
+	Challenger string `json:"challenger" gorm:"index"`
+	Opponent   string `json:"opponent" gorm:"index"`
+}
+
+// Pot returns what the winner of the challenge takes.
+func (c Challenge) Pot() uint64 {
+	return c.Stake * 2
+}
+
+// Resolve works out who won the challenge on the game.
+func (c *Challenge) Resolve(game *Game) {
+	c.ChallengerMatches = game.CheckGame(c.ChallengerPicks)
+	c.OpponentMatches = game.CheckGame(c.OpponentPicks)
+
+	challenger, opponent := uint64(c.ChallengerMatches), uint64(c.OpponentMatches)
+	if c.Rule == ChallengeRulePrize {
+		spots := uint8(len(c.ChallengerPicks))
+		challenger, opponent = winMatrix(spots, c.ChallengerMatches), winMatrix(spots, c.OpponentMatches)
+	}
+
+	switch {
+	case challenger > opponent:
+		c.Winner = c.Challenger
+	case opponent > challenger:
+		c.Winner = c.Opponent
+	default:
+		c.Winner = ""
+	}
+}
+
+func GetChallenge(db *gorm.DB, id uint64) (*Challenge, error) {
+	var challenge Challenge
+	err := db.First(&challenge, id).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &challenge, nil
+}
+
+// GetChallenges returns every challenge the user has made or been sent, newest
+// first.
+func GetChallenges(db *gorm.DB, user string) ([]Challenge, error) {
+	var challenges []Challenge
+	err := db.Where("challenger = ? OR opponent = ?", user, user).Order("id DESC").Find(&challenges).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return challenges, nil
+}
+
+// GetGameChallenges returns every challenge on the game with one of the
+// statuses.
+func GetGameChallenges(db *gorm.DB, gameId uint64, statuses ...string) ([]Challenge, error) {
+	var challenges []Challenge
+	err := db.Where("game_id = ? AND status IN ?", gameId, statuses).Order("id").Find(&challenges).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return challenges, nil
+}
+
+// CreateChallenge commits a new pending challenge to the database. The
+// challenge should have its game, stake, rule, challenger picks and users set,
+// everything else is filled in here.
+func CreateChallenge(db *gorm.DB, challenge Challenge) (*Challenge, error) {
+	// Sort selection
+	selection := challenge.ChallengerPicks
+	sort.Slice(selection, func(i, j int) bool { return selection[i] < selection[j] })
+
+	newChallenge := &challenge
+	newChallenge.ID = 0
+	newChallenge.CreatedAt = time.Now()
+	newChallenge.Status = ChallengePending
+	newChallenge.OpponentPicks = nil
+	newChallenge.Winner = ""
+
+	tx := db.Create(newChallenge)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	return newChallenge, nil
+}
+
+// AcceptChallenge records the opponent's selection against a pending
+// challenge. It returns ErrChallengeNotPending if the challenge has already
+// been accepted, cancelled or expired.
+func AcceptChallenge(db *gorm.DB, challenge *Challenge, selection []uint8) error {
+	sort.Slice(selection, func(i, j int) bool { return selection[i] < selection[j] })
+
+	now := time.Now()
+	tx := db.Model(&Challenge{}).
+		Where("id = ? AND status = ?", challenge.ID, ChallengePending).
+		Updates(map[string]interface{}{
+			"status":         ChallengeAccepted,
+			"opponent_picks": selection,
+			"accepted_at":    now,
+		})
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return ErrChallengeNotPending
+	}
+
+	challenge.Status = ChallengeAccepted
+	challenge.OpponentPicks = selection
+	challenge.AcceptedAt = now
+	return nil
+}
+
+// CancelChallenge cancels a pending challenge. It returns
+// ErrChallengeNotPending if the challenge isn't pending any more.
+func CancelChallenge(db *gorm.DB, challenge *Challenge) error {
+	return moveChallenge(db, challenge, ChallengePending, ChallengeCancelled)
+}
+
+// LockChallenges locks every accepted challenge on the game and expires every
+// one which is still pending, as the game's draw is starting.
+func LockChallenges(db *gorm.DB, gameId uint64) error {
+	err := db.Model(&Challenge{}).
+		Where("game_id = ? AND status = ?", gameId, ChallengeAccepted).
+		Update("status", ChallengeLocked).Error
+	if err != nil {
+		return err
+	}
+
+	return db.Model(&Challenge{}).
+		Where("game_id = ? AND status = ?", gameId, ChallengePending).
+		Update("status", ChallengeExpired).Error
+}
+
+// ExpireChallenge expires a challenge which was still pending when its game
+// was drawn.
+func ExpireChallenge(db *gorm.DB, challenge *Challenge) error {
+	return moveChallenge(db, challenge, ChallengePending, ChallengeExpired)
+}
+
+// VoidChallenge cancels a challenge whose game was abandoned, so neither side
+// wins and both stakes are given back. Challenges which were still pending are
+// expired instead.
+func VoidChallenge(db *gorm.DB, challenge *Challenge) error {
+	if challenge.Status == ChallengePending {
+		return ExpireChallenge(db, challenge)
+	}
+
+	return moveChallenge(db, challenge, challenge.Status, ChallengeCancelled)
+}
+
+// ResolveChallenge works out who won the challenge on the game and records it.
+// It returns ErrChallengeNotPending if the challenge had already been resolved.
+func ResolveChallenge(db *gorm.DB, challenge *Challenge, game *Game) error {
+	challenge.Resolve(game)
+
+	now := time.Now()
+	tx := db.Model(&Challenge{}).
+		Where("id = ? AND status IN ?", challenge.ID, []string{ChallengeAccepted, ChallengeLocked}).
+		Updates(map[string]interface{}{
+			"status":             ChallengeResolved,
+			"challenger_matches": challenge.ChallengerMatches,
+			"opponent_matches":   challenge.OpponentMatches,
+			"winner":             challenge.Winner,
+			"resolved_at":        now,
+		})
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return ErrChallengeNotPending
+	}
+
+	challenge.Status = ChallengeResolved
+	challenge.ResolvedAt = now
+	return nil
+}
+
+func moveChallenge(db *gorm.DB, challenge *Challenge, from, to string) error {
+	tx := db.Model(&Challenge{}).
+		Where("id = ? AND status = ?", challenge.ID, from).
+		Update("status", to)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return ErrChallengeNotPending
+	}
+
+	challenge.Status = to
+	return nil
+}
+
+// getChallengeNet returns how much the user has put up on challenges since the
+// given time, and how much they've been paid back from them. Stakes on
+// challenges which were cancelled or expired are refunded so aren't counted.
+func getChallengeNet(db *gorm.DB, user string, since time.Time) (uint64, uint64, error) {
+	var challenges []Challenge
+	err := db.Where("(challenger = ? AND created_at >= ? AND status NOT IN ?) OR (opponent = ? AND accepted_at >= ? AND status IN ?)",
+		user, since, []string{ChallengeCancelled, ChallengeExpired},
+		user, since, []string{ChallengeAccepted, ChallengeLocked, ChallengeResolved}).
+		Find(&challenges).Error
+	if err != nil {
+		return 0, 0, err
+	}
+
+	staked, won := uint64(0), uint64(0)
+	for _, challenge := range challenges {
+		staked += challenge.Stake
+
+		if challenge.Status != ChallengeResolved {
+			continue
+		}
+
+		switch challenge.Winner {
+		case user:
+			won += challenge.Pot()
+		case "":
+			won += challenge.Stake
+		}
+	}
+
+	return staked, won, nil
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Favourite is a named set of numbers a user has saved so they can place
+// cards with it again without typing the numbers back in.
+type Favourite struct {
+	ID        uint64 `gorm:"primarykey"`
+	CreatedAt time.Time
+
+	Name      string  `json:"name"`
+	Selection []uint8 `json:"selection"`
+
+	Tenant string `json:"-" gorm:"index;default:''"`
+	User   string `json:"user" gorm:"index"`
+}
+
+func GetFavourite(db *gorm.DB, id uint64) (*Favourite, error) {
+	var favourite Favourite
+	err := db.First(&favourite, id).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &favourite, nil
+}
+
+// GetFavourites returns every favourite saved by the user, oldest first.
+func GetFavourites(db *gorm.DB, user string) ([]Favourite, error) {
+	var favourites []Favourite
+	err := db.Where("user = ?", user).Order("id").Find(&favourites).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return favourites, nil
+}
+
+// CountFavourites returns how many favourites the user has saved.
+func CountFavourites(db *gorm.DB, user string) (int64, error) {
+	var count int64
+	err := db.Model(&Favourite{}).Where("user = ?", user).Count(&count).Error
+	return count, err
+}
+
+func SaveFavourite(db *gorm.DB, name string, selection []uint8, user string) (*Favourite, error) {
+	// Sort selection
+	sort.Slice(selection, func(i, j int) bool { return selection[i] < selection[j] })
+
+	favourite := &Favourite{
+		CreatedAt: time.Now(),
+		Name:      name,
+		Selection: selection,
+		User:      user,
+	}
+
+	tx := db.Create(favourite)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	return favourite, nil
+}
+
+// DeleteFavourite removes one of the user's favourites, it returns
+// gorm.ErrRecordNotFound if the user has no favourite with that ID.
+func DeleteFavourite(db *gorm.DB, id uint64, user string) error {
+	tx := db.Where("user = ?", user).Delete(&Favourite{}, id)
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	if tx.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -2,26 +2,59 @@ package models
 
 import (
 	"keno/internal/utils"
+	"math"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// Game statuses, a game is drawing until all of its numbers have been drawn.
+// Games whose draw was cut short, by the server restarting, are abandoned.
+const (
+	GameDrawing   = "DRAWING"
+	GameComplete  = "COMPLETE"
+	GameAbandoned = "ABANDONED"
+)
+
+// DrawSize is how many numbers are drawn in a game
+const DrawSize = 20
+
 type Game struct {
 	ID    uint64  `json:"id" gorm:"primary_key"`
 	Picks []uint8 `json:"picks"`
+
+	// StartTime and EndTime are when the draw started and when it was due to
+	// finish, games from before they were recorded don't have them.
+	StartTime time.Time `json:"start_time" gorm:"index"`
+	EndTime   time.Time `json:"end_time"`
+
+	// Settled is set once a result has been recorded for every card on the
+	// game and every settled hook has run on them.
+	Settled bool `json:"-" gorm:"index"`
+}
+
+// Finished reports whether every number has been drawn. Games which are no
+// longer being drawn but didn't finish were abandoned.
+func (g Game) Finished() bool {
+	return len(g.Picks) >= DrawSize
 }
 
 // CheckGame is a method that checks the game for matches against the selection
-// and returns the number of matches. This doesn't handle the payout, that is
-// done in the payout package.
+// and returns the number of matches. Each number is only counted once however
+// often it's repeated, so a selection never matches more numbers than it
+// really has. This doesn't handle the payout, that is done in the payout
+// package.
 func (g Game) CheckGame(selection []uint8) uint8 {
 	// Rolling Amount
 	matches := uint8(0)
 
-	for _, pick := range g.Picks {
-		if utils.Contains(selection, pick) {
+	var counted [math.MaxUint8 + 1]bool
+	for _, num := range selection {
+		if !counted[num] && utils.Contains(g.Picks, num) {
 			matches++
 		}
+		counted[num] = true
 	}
 
 	return matches
@@ -47,6 +80,98 @@ func GetLastGame(db *gorm.DB) (*Game, error) {
 	return &game, nil
 }
 
+// GameFilter narrows down the games returned by GetGames, fields which are
+// zero aren't filtered on.
+type GameFilter struct {
+	FromGame uint64
+	ToGame   uint64
+	Since    time.Time
+	Until    time.Time
+}
+
+// GetGames returns a page of the games matching the filter, newest first,
+// along with how many games match it altogether.
+func GetGames(db *gorm.DB, filter GameFilter, offset, limit int) ([]Game, int64, error) {
+	query := db.Model(&Game{})
+	if filter.FromGame > 0 {
+		query = query.Where("id >= ?", filter.FromGame)
+	}
+	if filter.ToGame > 0 {
+		query = query.Where("id <= ?", filter.ToGame)
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("start_time >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query = query.Where("start_time < ?", filter.Until)
+	}
+
+	// Counting and finding both start from the same conditions
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var games []Game
+	err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&games).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return games, total, nil
+}
+
+// GetUnsettledGames returns every game before lastGame which hasn't been
+// settled yet, in game order.
+func GetUnsettledGames(db *gorm.DB, lastGame uint64) ([]Game, error) {
+	var games []Game
+	err := db.Where("settled = ? AND id < ?", false, lastGame).Order("id").Find(&games).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return games, nil
+}
+
+// MarkGameSettled records that every card on the game has its result and the
+// settled hooks have all run.
+func MarkGameSettled(db *gorm.DB, id uint64) error {
+	tx := db.Model(&Game{}).Where("id = ?", id).Update("settled", true)
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	return nil
+}
+
+// SettledHook records that the named settled hook has run on a game, so a
+// game settled again after a crash only runs the hooks it was missing.
+type SettledHook struct {
+	GameID    uint64 `gorm:"primaryKey;autoIncrement:false"`
+	Name      string `gorm:"primaryKey"`
+	CreatedAt time.Time
+}
+
+// GetSettledHooks returns the names of the settled hooks which have already run
+// on the game.
+func GetSettledHooks(db *gorm.DB, gameId uint64) ([]string, error) {
+	var names []string
+	err := db.Model(&SettledHook{}).Where("game_id = ?", gameId).Pluck("name", &names).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return names, nil
+}
+
+// MarkHookDone records that the named settled hook has run on the game.
+func MarkHookDone(db *gorm.DB, gameId uint64, name string) error {
+	return db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&SettledHook{GameID: gameId, Name: name, CreatedAt: time.Now()}).Error
+}
+
 // CommitNewGame is a method that commits a new game to the database. You don't
 // need to have any picks to commit a new game, but you will need to commit
 // all 20 picks before you can check the game properly.
@@ -111,3 +236,16 @@ func winMatrix(numsPlayed, numsMatched uint8) uint64 {
 
 	return amount
 }
+
+// maxWin returns the biggest prize that can be won on a single game by
+// playing numsPlayed numbers.
+func maxWin(numsPlayed uint8) uint64 {
+	highest := uint64(0)
+	for _, amount := range matchMatrix[numsPlayed] {
+		if amount > highest {
+			highest = amount
+		}
+	}
+
+	return highest
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// History events, each one is something which happened to a user's cards or
+// subscriptions that they didn't do through the API themselves, or that
+// changed how much they have staked.
+const (
+	HistoryCardCancelled       = "CARD_CANCELLED"
+	HistorySubscriptionRenewed = "SUBSCRIPTION_RENEWED"
+	HistorySubscriptionSkipped = "SUBSCRIPTION_SKIPPED"
+	HistorySubscriptionStopped = "SUBSCRIPTION_STOPPED"
+)
+
+// History is a single entry in a user's activity log. Amount is the stake or
+// refund involved, if there was one.
+type History struct {
+	ID        uint64 `gorm:"primarykey"`
+	CreatedAt time.Time
+
+	Event          string `json:"event"`
+	CardID         uint64 `json:"card_id,omitempty"`
+	SubscriptionID uint64 `json:"subscription_id,omitempty"`
+	GameID         uint64 `json:"game_id,omitempty"`
+	Amount         uint64 `json:"amount,omitempty"`
+	Note           string `json:"note,omitempty"`
+
+	Tenant string `json:"-" gorm:"index;default:''"`
+	User   string `json:"user" gorm:"index"`
+}
+
+// LogHistory adds the entry to its user's history.
+func LogHistory(db *gorm.DB, entry History) error {
+	entry.ID = 0
+	entry.CreatedAt = time.Now()
+
+	tx := db.Create(&entry)
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	return nil
+}
+
+// GetHistory returns the user's most recent history entries, newest first.
+func GetHistory(db *gorm.DB, user string, limit int) ([]History, error) {
+	var history []History
+	err := db.Where("user = ?", user).Order("id DESC").Limit(limit).Find(&history).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
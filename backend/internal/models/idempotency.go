@@ -0,0 +1,78 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrIdempotencyKeyUsed is returned when reserving a key the user has already
+// used and which hasn't expired.
+var ErrIdempotencyKeyUsed = errors.New("idempotency key already used")
+
+// IdempotencyRecord stores the response to a request made with an
+// Idempotency-Key so a retry of it can be answered with the same response.
+// Status is 0 while the first request is still being handled.
+type IdempotencyRecord struct {
+	ID        uint64 `gorm:"primarykey"`
+	CreatedAt time.Time
+
+	Key         string `gorm:"uniqueIndex:idx_idempotency_tenant_key"`
+	RequestHash string
+	Status      int
+	Body        []byte
+
+	Tenant string `gorm:"uniqueIndex:idx_idempotency_tenant_key;default:''"`
+	User   string `gorm:"uniqueIndex:idx_idempotency_tenant_key;index"`
+}
+
+// ReserveIdempotencyKey records that the user is making a request with the key,
+// so it can only be handled once. Records created before expiry are removed
+// first, along with requests still being handled from before stale, which
+// must have been cut short by the server stopping. If the key is still in use
+// ErrIdempotencyKeyUsed is returned along with its record.
+func ReserveIdempotencyKey(db *gorm.DB, user, key, requestHash string, expiry, stale time.Time) (*IdempotencyRecord, error) {
+	err := db.Where("user = ? AND (created_at < ? OR (status = 0 AND created_at < ?))", user, expiry, stale).
+		Delete(&IdempotencyRecord{}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	record := &IdempotencyRecord{
+		CreatedAt:   time.Now(),
+		Key:         key,
+		RequestHash: requestHash,
+		User:        user,
+	}
+
+	tx := db.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	if tx.RowsAffected > 0 {
+		return record, nil
+	}
+
+	// Someone got there first, return their record
+	existing := &IdempotencyRecord{}
+	err = db.Where("user = ? AND key = ?", user, key).First(existing).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return existing, ErrIdempotencyKeyUsed
+}
+
+// CompleteIdempotencyKey stores the response to the request made with the key.
+func CompleteIdempotencyKey(db *gorm.DB, id uint64, status int, body []byte) error {
+	return db.Model(&IdempotencyRecord{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"status": status, "body": body}).Error
+}
+
+// ReleaseIdempotencyKey removes the record so the key can be used again, for
+// requests which failed without doing anything.
+func ReleaseIdempotencyKey(db *gorm.DB, id uint64) error {
+	return db.Delete(&IdempotencyRecord{}, id).Error
+}
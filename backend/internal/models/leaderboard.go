@@ -0,0 +1,359 @@
+package models
+
+import (
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Leaderboard periods, each covers results settled since the start of the
+// current UTC day, week (starting on Monday) or month, or every result.
+const (
+	PeriodDaily   = "DAILY"
+	PeriodWeekly  = "WEEKLY"
+	PeriodMonthly = "MONTHLY"
+	PeriodAllTime = "ALL_TIME"
+)
+
+// Leaderboards, each ranks users by a different value.
+const (
+	// BoardBiggestWin is the biggest prize won on a single game
+	BoardBiggestWin = "BIGGEST_WIN"
+	// BoardBestReturn is the percentage of what was staked which was won back
+	BoardBestReturn = "BEST_RETURN"
+	// BoardTenSpotHits is how many times all ten numbers of a 10-spot were drawn
+	BoardTenSpotHits = "TEN_SPOT_HITS"
+	// BoardNetProfit is how much more was won than staked
+	BoardNetProfit = "NET_PROFIT"
+)
+
+var (
+	Periods = []string{PeriodDaily, PeriodWeekly, PeriodMonthly, PeriodAllTime}
+	Boards  = []string{BoardBiggestWin, BoardBestReturn, BoardTenSpotHits, BoardNetProfit}
+
+	// LeaderboardSize is how many users are kept on each leaderboard
+	LeaderboardSize = 25
+
+	// MinReturnStake is how much a user has to have staked in the period to
+	// be ranked by their return, so a single lucky card doesn't top it
+	MinReturnStake uint64 = 100
+)
+
+// LeaderboardEntry is a user's place on one of the leaderboards. The entries
+// are ranked from the LeaderboardTotals and replaced whenever a game is
+// settled. Syndicate cards aren't counted as they belong to several users, and
+// neither are tournament cards or games which were abandoned.
+type LeaderboardEntry struct {
+	ID        uint64 `gorm:"primarykey"`
+	UpdatedAt time.Time
+
+	Period string `json:"period" gorm:"index:idx_leaderboard"`
+	Board  string `json:"board" gorm:"index:idx_leaderboard"`
+	Rank   int    `json:"rank"`
+	Value  int64  `json:"value"`
+
+	Tenant string `json:"-" gorm:"index;default:''"`
+	User   string `json:"user"`
+}
+
+// LeaderboardTotal is a user's results added up over the period which began
+// at Start. The totals are added to as each game is settled, so ranking the
+// leaderboards never has to go back over every result.
+type LeaderboardTotal struct {
+	Period string    `gorm:"primaryKey"`
+	Start  time.Time `gorm:"primaryKey"`
+	Tenant string    `gorm:"primaryKey;default:''"`
+	User   string    `gorm:"primaryKey"`
+
+	Biggest     uint64
+	Staked      uint64
+	Won         uint64
+	TenSpotHits uint64
+}
+
+// LeaderboardGame records that a game's results have been added to the
+// LeaderboardTotals, so adding them again after a crash doesn't count them
+// twice.
+type LeaderboardGame struct {
+	GameID uint64 `gorm:"primaryKey;autoIncrement:false"`
+}
+
+// userTotals are a user's results added up over a period
+type userTotals struct {
+	Tenant      string
+	User        string
+	Biggest     uint64
+	Staked      uint64
+	Won         uint64
+	TenSpotHits uint64
+}
+
+// periodStart returns when the period containing now started.
+func periodStart(period string, now time.Time) time.Time {
+	now = now.UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch period {
+	case PeriodDaily:
+		return day
+	case PeriodWeekly:
+		// Weeks start on Monday
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case PeriodMonthly:
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Time{}
+	}
+}
+
+// GetLeaderboard returns the entries on the board for the period, in rank
+// order.
+func GetLeaderboard(db *gorm.DB, board, period string) ([]LeaderboardEntry, error) {
+	var entries []LeaderboardEntry
+	err := db.Where("board = ? AND period = ?", board, period).Order("rank").Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// AddToLeaderboards adds the settled results of a game to each user's totals
+// for every period, then ranks the leaderboards of the tenants they belong to
+// again. A game's results are only ever added once, however often it's called
+// with them. Leaderboards still showing a period which has ended are ranked
+// again too, so they start over even if nobody has played since.
+func AddToLeaderboards(db *gorm.DB, gameId uint64, results []CardResult, now time.Time) error {
+	// Add up each user's results first so each total is only updated once
+	tenants := make(map[string]bool)
+	added := make(map[[2]string]*userTotals)
+	keys := make([][2]string, 0)
+	for _, result := range results {
+		if result.SyndicateID != 0 || result.TournamentID != 0 || result.Refund != 0 {
+			continue
+		}
+		tenants[result.Tenant] = true
+
+		key := [2]string{result.Tenant, result.User}
+		total, ok := added[key]
+		if !ok {
+			total = &userTotals{Tenant: result.Tenant, User: result.User}
+			added[key] = total
+			keys = append(keys, key)
+		}
+
+		if result.Prize > total.Biggest {
+			total.Biggest = result.Prize
+		}
+		total.Staked += result.Stake
+		total.Won += result.Prize
+		if result.Spots == 10 && result.Matches == 10 {
+			total.TenSpotHits++
+		}
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&LeaderboardGame{GameID: gameId})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			keys = nil
+		}
+
+		for _, period := range Periods {
+			start := periodStart(period, now)
+
+			// Totals from periods which have ended won't be ranked again
+			if err := tx.Where("period = ? AND start < ?", period, start).Delete(&LeaderboardTotal{}).Error; err != nil {
+				return err
+			}
+
+			for _, key := range keys {
+				if err := addLeaderboardTotal(tx, period, start, *added[key]); err != nil {
+					return err
+				}
+			}
+		}
+
+		// Every period starts again at the start of a day at the latest
+		var stale []string
+		err := tx.Model(&LeaderboardEntry{}).
+			Where("period <> ? AND updated_at < ?", PeriodAllTime, periodStart(PeriodDaily, now)).
+			Distinct().Pluck("tenant", &stale).Error
+		if err != nil {
+			return err
+		}
+		for _, tenant := range stale {
+			tenants[tenant] = true
+		}
+
+		for tenant := range tenants {
+			if err := rankTenant(tx, tenant, now); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// BackfillLeaderboardTotals adds up the totals for the current periods from
+// the results which were settled before the totals were kept, and records
+// their games as added. It does nothing once there are totals.
+func BackfillLeaderboardTotals(db *gorm.DB, now time.Time) error {
+	var count int64
+	if err := db.Model(&LeaderboardTotal{}).Count(&count).Error; err != nil || count > 0 {
+		return err
+	}
+
+	totals := make([]LeaderboardTotal, 0)
+	for _, period := range Periods {
+		var periodTotals []userTotals
+		err := db.Model(&CardResult{}).
+			Select("tenant, user, MAX(prize) AS biggest, SUM(stake) AS staked, SUM(prize) AS won, "+
+				"SUM(CASE WHEN spots = 10 AND matches = 10 THEN 1 ELSE 0 END) AS ten_spot_hits").
+			Where("syndicate_id = 0 AND tournament_id = 0 AND refund = 0 AND created_at >= ?", periodStart(period, now)).
+			Group("tenant, user").
+			Scan(&periodTotals).Error
+		if err != nil {
+			return err
+		}
+
+		for _, total := range periodTotals {
+			totals = append(totals, LeaderboardTotal{
+				Period:      period,
+				Start:       periodStart(period, now),
+				Tenant:      total.Tenant,
+				User:        total.User,
+				Biggest:     total.Biggest,
+				Staked:      total.Staked,
+				Won:         total.Won,
+				TenSpotHits: total.TenSpotHits,
+			})
+		}
+	}
+
+	if len(totals) == 0 {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(&totals, 100).Error; err != nil {
+			return err
+		}
+
+		// The games counted here mustn't be added again when they're settled
+		return tx.Exec(`INSERT INTO leaderboard_games (game_id)
+			SELECT DISTINCT game_id FROM card_results WHERE true
+			ON CONFLICT DO NOTHING`).Error
+	})
+}
+
+// addLeaderboardTotal adds to the user's total for the period starting at
+// start, creating it if this is their first result in the period.
+func addLeaderboardTotal(db *gorm.DB, period string, start time.Time, added userTotals) error {
+	return db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "period"}, {Name: "start"}, {Name: "tenant"}, {Name: "user"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"biggest":       gorm.Expr("MAX(biggest, excluded.biggest)"),
+			"staked":        gorm.Expr("staked + excluded.staked"),
+			"won":           gorm.Expr("won + excluded.won"),
+			"ten_spot_hits": gorm.Expr("ten_spot_hits + excluded.ten_spot_hits"),
+		}),
+	}).Create(&LeaderboardTotal{
+		Period:      period,
+		Start:       start,
+		Tenant:      added.Tenant,
+		User:        added.User,
+		Biggest:     added.Biggest,
+		Staked:      added.Staked,
+		Won:         added.Won,
+		TenSpotHits: added.TenSpotHits,
+	}).Error
+}
+
+// rankTenant replaces the tenant's leaderboards with the users ranked by their
+// totals for the current periods.
+func rankTenant(db *gorm.DB, tenant string, now time.Time) error {
+	entries := make([]LeaderboardEntry, 0)
+	for _, period := range Periods {
+		var totals []userTotals
+		err := db.Model(&LeaderboardTotal{}).
+			Where("period = ? AND start = ? AND tenant = ?", period, periodStart(period, now), tenant).
+			Scan(&totals).Error
+		if err != nil {
+			return err
+		}
+
+		for _, board := range Boards {
+			entries = append(entries, rankUsers(totals, board, period, now)...)
+		}
+	}
+
+	if err := db.Where("tenant = ?", tenant).Delete(&LeaderboardEntry{}).Error; err != nil {
+		return err
+	}
+
+	if len(entries) == 0 {
+		return nil
+	}
+
+	return db.Create(&entries).Error
+}
+
+// rankUsers returns the top LeaderboardSize users on the board, highest value
+// first. The totals have to all belong to the same tenant. Users with the same
+// value are ranked by name so the order is stable.
+func rankUsers(totals []userTotals, board, period string, now time.Time) []LeaderboardEntry {
+	entries := make([]LeaderboardEntry, 0, len(totals))
+	for _, total := range totals {
+		var value int64
+		switch board {
+		case BoardBiggestWin:
+			value = int64(total.Biggest)
+		case BoardBestReturn:
+			if total.Staked < MinReturnStake {
+				continue
+			}
+			value = int64(total.Won * 100 / total.Staked)
+		case BoardTenSpotHits:
+			value = int64(total.TenSpotHits)
+		case BoardNetProfit:
+			value = int64(total.Won) - int64(total.Staked)
+		}
+
+		// Nobody wants to be on a leaderboard for winning nothing
+		if value <= 0 && board != BoardNetProfit {
+			continue
+		}
+
+		entries = append(entries, LeaderboardEntry{
+			UpdatedAt: now,
+			Period:    period,
+			Board:     board,
+			Value:     value,
+			Tenant:    total.Tenant,
+			User:      total.User,
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Value != entries[j].Value {
+			return entries[i].Value > entries[j].Value
+		}
+		return entries[i].User < entries[j].User
+	})
+
+	if len(entries) > LeaderboardSize {
+		entries = entries[:LeaderboardSize]
+	}
+
+	for i := range entries {
+		entries[i].Rank = i + 1
+	}
+
+	return entries
+}
@@ -0,0 +1,161 @@
+package models
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupLeaderboards(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "keno.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterTenantScope(db); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&CardResult{}, &LeaderboardEntry{}, &LeaderboardTotal{}, &LeaderboardGame{}); err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+func leaderboardValues(t *testing.T, db *gorm.DB, tenant, board, period string) map[string]int64 {
+	t.Helper()
+
+	entries, err := GetLeaderboard(ForTenant(db, tenant), board, period)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values := make(map[string]int64)
+	for _, entry := range entries {
+		values[entry.User] = entry.Value
+	}
+
+	return values
+}
+
+func TestAddToLeaderboardsKeepsRunningTotals(t *testing.T) {
+	db := setupLeaderboards(t)
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+
+	err := AddToLeaderboards(db, 1, []CardResult{
+		{User: "a", Stake: 100, Prize: 300},
+		{User: "a", Stake: 100, Prize: 0},
+		{User: "b", Stake: 50, Prize: 20},
+		{User: "c", Stake: 50, Prize: 500, SyndicateID: 1},
+		{User: "d", Stake: 50, Refund: 50},
+	}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = AddToLeaderboards(db, 2, []CardResult{
+		{User: "a", Stake: 100, Prize: 200},
+		{User: "b", Stake: 10, Prize: 0, Tenant: "guild"},
+	}, now.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	profit := leaderboardValues(t, db, DefaultTenant, BoardNetProfit, PeriodDaily)
+	if len(profit) != 2 || profit["a"] != 200 || profit["b"] != -30 {
+		t.Errorf("got net profits %v, want a on 200 and b on -30", profit)
+	}
+
+	biggest := leaderboardValues(t, db, DefaultTenant, BoardBiggestWin, PeriodAllTime)
+	if biggest["a"] != 300 {
+		t.Errorf("got biggest win %d for a, want 300", biggest["a"])
+	}
+
+	guild := leaderboardValues(t, db, "guild", BoardNetProfit, PeriodDaily)
+	if len(guild) != 1 || guild["b"] != -10 {
+		t.Errorf("got net profits %v in the guild, want b on -10", guild)
+	}
+}
+
+func TestAddToLeaderboardsStartsNewPeriods(t *testing.T) {
+	db := setupLeaderboards(t)
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+
+	if err := AddToLeaderboards(db, 1, []CardResult{{User: "a", Stake: 10, Prize: 50}}, now); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the other tenant plays the next day
+	tomorrow := now.AddDate(0, 0, 1)
+	if err := AddToLeaderboards(db, 2, []CardResult{{User: "b", Stake: 10, Prize: 50, Tenant: "guild"}}, tomorrow); err != nil {
+		t.Fatal(err)
+	}
+
+	if daily := leaderboardValues(t, db, DefaultTenant, BoardBiggestWin, PeriodDaily); len(daily) != 0 {
+		t.Errorf("got daily entries %v from the day before", daily)
+	}
+	if weekly := leaderboardValues(t, db, DefaultTenant, BoardBiggestWin, PeriodWeekly); weekly["a"] != 50 {
+		t.Errorf("got weekly entries %v, want a on 50", weekly)
+	}
+
+	var count int64
+	if err := db.Model(&LeaderboardTotal{}).Where("period = ?", PeriodDaily).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("%d daily totals were kept, want only today's", count)
+	}
+}
+
+func TestBackfillLeaderboardTotals(t *testing.T) {
+	db := setupLeaderboards(t)
+	now := time.Now()
+
+	results := []CardResult{
+		{CardID: 1, GameID: 1, CreatedAt: now, User: "a", Stake: 10, Prize: 40},
+		{CardID: 2, GameID: 1, CreatedAt: now.AddDate(-1, 0, 0), User: "a", Stake: 10, Prize: 0},
+	}
+	if err := db.Create(&results).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := BackfillLeaderboardTotals(db, now); err != nil {
+		t.Fatal(err)
+	}
+
+	// The backfilled game was still being settled, so is added again
+	if err := AddToLeaderboards(db, 1, results, now); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddToLeaderboards(db, 3, []CardResult{{User: "a", Stake: 10, Prize: 0}}, now); err != nil {
+		t.Fatal(err)
+	}
+
+	if daily := leaderboardValues(t, db, DefaultTenant, BoardNetProfit, PeriodDaily); daily["a"] != 20 {
+		t.Errorf("got a daily net profit of %d, want 20", daily["a"])
+	}
+	if allTime := leaderboardValues(t, db, DefaultTenant, BoardNetProfit, PeriodAllTime); allTime["a"] != 10 {
+		t.Errorf("got an all time net profit of %d, want 10", allTime["a"])
+	}
+}
+
+func TestAddToLeaderboardsOnlyCountsGamesOnce(t *testing.T) {
+	db := setupLeaderboards(t)
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	results := []CardResult{{GameID: 1, User: "a", Stake: 10, Prize: 50}}
+
+	// Settlement crashed after adding the game, so it's added again
+	for i := 0; i < 2; i++ {
+		if err := AddToLeaderboards(db, 1, results, now); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if profit := leaderboardValues(t, db, DefaultTenant, BoardNetProfit, PeriodAllTime); profit["a"] != 40 {
+		t.Errorf("got a net profit of %d, want 40", profit["a"])
+	}
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"errors"
+	"math"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrPayoutOverflow is returned when a card's top prize is too large to be
+	// counted towards its games' liability.
+	ErrPayoutOverflow = errors.New("payout overflowed")
+
+	// ErrLiabilityExceeded is returned when a card would push the liability of
+	// one of its games over its cap.
+	ErrLiabilityExceeded = errors.New("game liability exceeded")
+)
+
+// GameLiability is the most that could be paid out on a game to one tenant's
+// cards, if every one of them won the top prize. It's kept up to date as cards
+// are placed and cancelled, so checking a new card doesn't mean adding up
+// every other card on its games.
+type GameLiability struct {
+	GameID uint64 `gorm:"primaryKey;autoIncrement:false"`
+	Tenant string `gorm:"primaryKey;default:''"`
+	Amount uint64
+}
+
+// LiabilityCaps are the most the cards on a single game can pay out. Total
+// counts the cards of every tenant and Tenant only those of the card's own
+// tenant. A cap of zero isn't checked.
+type LiabilityCaps struct {
+	Total  uint64
+	Tenant uint64
+}
+
+// addLiability adds the card's top prize to the liability of each game from
+// firstGame up to lastGame, or takes it off again when sign is -1 for games
+// which were cancelled. Adding is checked against the caps in the same
+// statement which adds to the liability, so cards placed at the same time
+// can't both squeeze under a cap, and ErrLiabilityExceeded is returned if any
+// game would go over. Tournament cards don't pay out so aren't counted.
+func addLiability(db *gorm.DB, card Card, firstGame, lastGame uint64, sign int64, caps LiabilityCaps) error {
+	if card.TournamentID != 0 || firstGame >= lastGame {
+		return nil
+	}
+
+	payout, ok := card.MaxPayout()
+	if !ok || payout > math.MaxInt64 {
+		return ErrPayoutOverflow
+	}
+	amount := int64(payout) * sign
+
+	rows := make([]GameLiability, 0, lastGame-firstGame)
+	for gameNum := firstGame; gameNum < lastGame; gameNum++ {
+		rows = append(rows, GameLiability{GameID: gameNum, Tenant: card.Tenant})
+	}
+
+	// Create the rows which are missing, then add to every one of them
+	err := AllTenants(db).Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	update := AllTenants(db).Model(&GameLiability{}).
+		Where("tenant = ? AND game_id >= ? AND game_id < ?", card.Tenant, firstGame, lastGame)
+	checked := sign > 0 && (caps.Total > 0 || caps.Tenant > 0)
+	if sign > 0 && caps.Tenant > 0 {
+		update = update.Where("amount + ? <= ?", amount, caps.Tenant)
+	}
+	if sign > 0 && caps.Total > 0 {
+		update = update.Where(`(SELECT SUM(l.amount) FROM game_liabilities l
+			WHERE l.game_id = game_liabilities.game_id) + ? <= ?`, amount, caps.Total)
+	}
+
+	tx := update.Update("amount", gorm.Expr("amount + ?", amount))
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if checked && tx.RowsAffected < int64(lastGame-firstGame) {
+		return ErrLiabilityExceeded
+	}
+
+	return nil
+}
+
+// PruneGameLiabilities removes the liability of every game up to and
+// including lastGame, which can't take any more cards.
+func PruneGameLiabilities(db *gorm.DB, lastGame uint64) error {
+	return AllTenants(db).Where("game_id <= ?", lastGame).Delete(&GameLiability{}).Error
+}
+
+// BackfillGameLiabilities works out the liability of the games still to be
+// drawn from the cards already placed on them, for databases from before it
+// was kept. It only does anything the first time it's run.
+func BackfillGameLiabilities(db *gorm.DB) error {
+	db = AllTenants(db)
+
+	var count int64
+	if err := db.Model(&GameLiability{}).Count(&count).Error; err != nil || count > 0 {
+		return err
+	}
+
+	// Betting on the latest game has already closed, so only the games after
+	// it can take new cards
+	var nextGame uint64
+	if err := db.Model(&Game{}).Select("COALESCE(MAX(id), 0) + 1").Scan(&nextGame).Error; err != nil {
+		return err
+	}
+
+	var cards []Card
+	return db.Where("last_game > ? AND tournament_id = 0", nextGame).
+		FindInBatches(&cards, 100, func(tx *gorm.DB, batch int) error {
+			for _, card := range cards {
+				first := card.StartGame
+				if first < nextGame {
+					first = nextGame
+				}
+
+				if err := addLiability(db, card, first, card.LastGame, 1, LiabilityCaps{}); err != nil {
+					log.WithField("src", "models.BackfillGameLiabilities").WithField("card", card.ID).WithError(err).Error("Error adding card liability")
+					return err
+				}
+			}
+			return nil
+		}).Error
+}
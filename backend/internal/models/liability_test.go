@@ -0,0 +1,79 @@
+package models
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupLiabilities(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "keno.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterTenantScope(db); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&GameLiability{}); err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+func TestAddLiabilityChecksCaps(t *testing.T) {
+	db := setupLiabilities(t)
+	card := Card{Selection: []uint8{1, 2, 3, 4}, PerGame: 10}
+	payout, _ := card.MaxPayout()
+
+	if err := addLiability(db, card, 1, 3, 1, LiabilityCaps{Tenant: payout}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The tenant's own cap is already full on game 2
+	err := addLiability(db, card, 2, 4, 1, LiabilityCaps{Tenant: payout})
+	if !errors.Is(err, ErrLiabilityExceeded) {
+		t.Errorf("got %v adding past the tenant cap, want ErrLiabilityExceeded", err)
+	}
+
+	// Other tenants count towards the total cap but not the tenant cap
+	guild := card
+	guild.Tenant = "guild"
+	err = addLiability(db, guild, 1, 2, 1, LiabilityCaps{Total: payout * 3 / 2, Tenant: payout})
+	if !errors.Is(err, ErrLiabilityExceeded) {
+		t.Errorf("got %v adding past the total cap, want ErrLiabilityExceeded", err)
+	}
+	if err := addLiability(db, guild, 1, 2, 1, LiabilityCaps{Total: payout * 2, Tenant: payout}); err != nil {
+		t.Errorf("got %v adding under both caps", err)
+	}
+
+	// Cancelling isn't capped
+	if err := addLiability(db, card, 1, 3, -1, LiabilityCaps{Tenant: 1}); err != nil {
+		t.Errorf("got %v taking liability off", err)
+	}
+}
+
+func TestPruneGameLiabilities(t *testing.T) {
+	db := setupLiabilities(t)
+	card := Card{Selection: []uint8{1, 2, 3, 4}, PerGame: 10, Tenant: "guild"}
+
+	if err := addLiability(db, card, 1, 5, 1, LiabilityCaps{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := PruneGameLiabilities(db, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	var games []GameLiability
+	if err := AllTenants(db).Order("game_id").Find(&games).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(games) != 2 || games[0].GameID != 3 || games[1].GameID != 4 {
+		t.Errorf("got liabilities %v after pruning, want games 3 and 4", games)
+	}
+}
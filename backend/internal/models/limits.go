@@ -0,0 +1,172 @@
+package models
+
+import (
+	"errors"
+	"keno/internal/utils"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Limit is a single responsible gambling limit, an Amount of zero means there
+// isn't one. Lowering a limit takes effect straight away, raising or removing
+// one is held in Pending until PendingAt.
+type Limit struct {
+	Amount    uint64    `json:"amount"`
+	Pending   uint64    `json:"pending"`
+	PendingAt time.Time `json:"pending_at"`
+}
+
+// Current returns the amount of the limit in effect at now.
+func (l Limit) Current(now time.Time) uint64 {
+	if !l.PendingAt.IsZero() && !now.Before(l.PendingAt) {
+		return l.Pending
+	}
+
+	return l.Amount
+}
+
+// Set changes the limit to amount, straight away if it makes the limit
+// stricter, otherwise once delay has passed.
+func (l *Limit) Set(amount uint64, now time.Time, delay time.Duration) {
+	// Asking for the same raise again doesn't start its delay over
+	if !l.PendingAt.IsZero() && now.Before(l.PendingAt) && amount == l.Pending {
+		return
+	}
+
+	l.Amount = l.Current(now)
+	l.Pending = 0
+	l.PendingAt = time.Time{}
+
+	stricter := amount != 0 && (l.Amount == 0 || amount < l.Amount)
+	if stricter || amount == l.Amount {
+		l.Amount = amount
+		return
+	}
+
+	l.Pending = amount
+	l.PendingAt = now.Add(delay)
+}
+
+// Limits are the responsible gambling controls a user has put on themselves.
+// Loss limits cap how much can be lost over a rolling day, week or month,
+// MaxStake caps the cost of a single card. No cards can be placed while
+// cooling off or self excluded.
+type Limits struct {
+	User      string `gorm:"primarykey"`
+	UpdatedAt time.Time
+
+	DailyLoss   Limit `json:"daily_loss" gorm:"embedded;embeddedPrefix:daily_loss_"`
+	WeeklyLoss  Limit `json:"weekly_loss" gorm:"embedded;embeddedPrefix:weekly_loss_"`
+	MonthlyLoss Limit `json:"monthly_loss" gorm:"embedded;embeddedPrefix:monthly_loss_"`
+	MaxStake    Limit `json:"max_stake" gorm:"embedded;embeddedPrefix:max_stake_"`
+
+	CoolOffUntil  time.Time `json:"cool_off_until"`
+	ExcludedUntil time.Time `json:"excluded_until"`
+}
+
+// GetLimits returns the user's limits, users who haven't set any get empty
+// limits.
+func GetLimits(db *gorm.DB, user string) (*Limits, error) {
+	var limits Limits
+	err := db.Where("user = ?", user).Limit(1).Find(&limits).Error
+	if err != nil {
+		return nil, err
+	}
+
+	limits.User = user
+	return &limits, nil
+}
+
+func SaveLimits(db *gorm.DB, limits *Limits) error {
+	limits.UpdatedAt = time.Now()
+
+	tx := db.Clauses(clause.OnConflict{UpdateAll: true}).Create(limits)
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	return nil
+}
+
+// GetNetLoss returns how much the user has staked on cards, syndicate shares
+// and challenges since the given time, less anything they've won on them so
+// far. Games which were cancelled aren't counted, as their stake was refunded,
+// and neither are shares in syndicates which were cancelled or tournament
+// cards, which are played with the tournament's bankroll.
+func GetNetLoss(db *gorm.DB, user string, since time.Time) (uint64, error) {
+	// Syndicate cards are counted through the members' shares instead
+	var cards []Card
+	err := db.Where("user = ? AND created_at >= ? AND syndicate_id = 0 AND tournament_id = 0", user, since).Find(&cards).Error
+	if err != nil {
+		return 0, err
+	}
+
+	staked := uint64(0)
+	for _, card := range cards {
+		cost, ok := card.TotalCost()
+		if ok {
+			staked, ok = utils.AddUint64(staked, cost)
+		}
+		if !ok {
+			return 0, errors.New("net loss overflowed")
+		}
+	}
+
+	var shares uint64
+	err = db.Model(&SyndicateShare{}).
+		Joins("JOIN syndicates ON syndicates.id = syndicate_shares.syndicate_id").
+		Where("syndicate_shares.user = ? AND syndicate_shares.created_at >= ? AND syndicates.status <> ?", user, since, SyndicateCancelled).
+		Select("COALESCE(SUM(syndicate_shares.cost), 0)").
+		Scan(&shares).Error
+	if err != nil {
+		return 0, err
+	}
+
+	// Winnings are only counted from the cards and shares bought since then,
+	// as the stakes of older ones aren't counted either
+	var won, paid uint64
+	err = db.Model(&CardResult{}).
+		Joins("JOIN cards ON cards.id = card_results.card_id").
+		Where("card_results.user = ? AND cards.created_at >= ? AND card_results.syndicate_id = 0 AND card_results.tournament_id = 0", user, since).
+		Select("COALESCE(SUM(card_results.prize + card_results.refund), 0)").
+		Scan(&won).Error
+	if err != nil {
+		return 0, err
+	}
+
+	err = db.Model(&SyndicatePayout{}).
+		Where("user = ? AND syndicate_id IN (?)", user,
+			db.Model(&SyndicateShare{}).Select("syndicate_id").Where("user = ? AND created_at >= ?", user, since)).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&paid).Error
+	if err != nil {
+		return 0, err
+	}
+
+	challenged, returned, err := getChallengeNet(db, user, since)
+	if err != nil {
+		return 0, err
+	}
+
+	staked, ok := utils.AddUint64(staked, shares)
+	if ok {
+		staked, ok = utils.AddUint64(staked, challenged)
+	}
+	if ok {
+		won, ok = utils.AddUint64(won, paid)
+	}
+	if ok {
+		won, ok = utils.AddUint64(won, returned)
+	}
+	if !ok {
+		return 0, errors.New("net loss overflowed")
+	}
+
+	if won >= staked {
+		return 0, nil
+	}
+
+	return staked - won, nil
+}
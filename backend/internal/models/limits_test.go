@@ -0,0 +1,67 @@
+package models
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestLimitSetKeepsPendingRaise(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	limit := Limit{Amount: 100}
+
+	limit.Set(200, now, time.Hour)
+	limit.Set(200, now.Add(30*time.Minute), time.Hour)
+	if !limit.PendingAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("asking for the same raise again moved it to %v", limit.PendingAt)
+	}
+
+	limit.Set(300, now.Add(30*time.Minute), time.Hour)
+	if limit.Pending != 300 || !limit.PendingAt.Equal(now.Add(90*time.Minute)) {
+		t.Errorf("got %d pending at %v, want a bigger raise to start over", limit.Pending, limit.PendingAt)
+	}
+}
+
+func TestGetNetLossOnlyCountsWinsFromTheWindow(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "keno.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterTenantScope(db); err != nil {
+		t.Fatal(err)
+	}
+	err = db.AutoMigrate(&Card{}, &CardResult{}, &Syndicate{}, &SyndicateShare{}, &SyndicatePayout{}, &Challenge{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	since := now.Add(-24 * time.Hour)
+	cards := []Card{
+		{ID: 1, CreatedAt: now.Add(-48 * time.Hour), TicketRef: "old", Selection: []uint8{1}, StartGame: 1, LastGame: 2, PerGame: 10, User: "a"},
+		{ID: 2, CreatedAt: now, TicketRef: "new", Selection: []uint8{1}, StartGame: 2, LastGame: 3, PerGame: 100, User: "a"},
+	}
+	if err := db.Create(&cards).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// The old card is only settled inside the window
+	results := []CardResult{
+		{CardID: 1, GameID: 1, CreatedAt: now, Stake: 10, Prize: 500, User: "a"},
+		{CardID: 2, GameID: 2, CreatedAt: now, Stake: 100, Prize: 20, User: "a"},
+	}
+	if err := db.Create(&results).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	loss, err := GetNetLoss(ForTenant(db, DefaultTenant), "a", since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loss != 80 {
+		t.Errorf("got a net loss of %d, want 80", loss)
+	}
+}
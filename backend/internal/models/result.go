@@ -0,0 +1,102 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CardResult is how a card did on a single game, recorded when the game is
+// settled. There is only ever one result for each card and game. Matches is
+// how many of the card's whole selection were drawn, even for system cards,
+// and Stake is what the card cost for the game. Results for syndicate cards are
+// paid out to the members as SyndicatePayouts, and results for tournament cards
+// only count towards the tournament. Games which were abandoned part way
+// through their draw have results which refund the stake rather than win
+// anything.
+type CardResult struct {
+	ID        uint64 `gorm:"primarykey"`
+	CreatedAt time.Time
+
+	CardID       uint64 `json:"card_id" gorm:"uniqueIndex:idx_card_result"`
+	SyndicateID  uint64 `json:"syndicate_id,omitempty" gorm:"default:0"`
+	TournamentID uint64 `json:"tournament_id,omitempty" gorm:"default:0"`
+	GameID       uint64 `json:"game_id" gorm:"uniqueIndex:idx_card_result;index"`
+	Spots        uint8  `json:"spots"`
+	Matches      uint8  `json:"matches"`
+	Stake        uint64 `json:"stake"`
+	Prize        uint64 `json:"prize"`
+	Refund       uint64 `json:"refund,omitempty" gorm:"default:0"`
+
+	Tenant string `json:"-" gorm:"index;default:''"`
+	User   string `json:"user" gorm:"index"`
+}
+
+// SaveResults commits the results for a game to the database, skipping any
+// which have already been recorded so a game can safely be settled more than
+// once. It returns the results which were new.
+func SaveResults(db *gorm.DB, gameId uint64, results []CardResult) ([]CardResult, error) {
+	if len(results) == 0 {
+		return results, nil
+	}
+
+	cardIds := make([]uint64, 0, len(results))
+	for _, result := range results {
+		cardIds = append(cardIds, result.CardID)
+	}
+
+	var saved []uint64
+	err := db.Model(&CardResult{}).
+		Where("game_id = ? AND card_id IN ?", gameId, cardIds).
+		Pluck("card_id", &saved).Error
+	if err != nil {
+		return nil, err
+	}
+
+	skip := make(map[uint64]bool, len(saved))
+	for _, cardId := range saved {
+		skip[cardId] = true
+	}
+
+	fresh := make([]CardResult, 0, len(results))
+	for _, result := range results {
+		if !skip[result.CardID] {
+			fresh = append(fresh, result)
+		}
+	}
+
+	if len(fresh) == 0 {
+		return fresh, nil
+	}
+
+	tx := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&fresh)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	return fresh, nil
+}
+
+// GetCardResults returns the results of every settled game on the card, in
+// game order.
+func GetCardResults(db *gorm.DB, cardId uint64) ([]CardResult, error) {
+	var results []CardResult
+	err := db.Where("card_id = ?", cardId).Order("game_id").Find(&results).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// GetGameResults returns the result of every card on the game, in card order.
+func GetGameResults(db *gorm.DB, gameId uint64) ([]CardResult, error) {
+	var results []CardResult
+	err := db.Where("game_id = ?", gameId).Order("card_id").Find(&results).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
@@ -7,6 +7,11 @@ type Message struct {
 	// The message type
 	Type string        `json:"type"`
 	Body StreamMessage `json:"body"`
+
+	// Seq numbers the game messages in the order they were sent, so clients
+	// which reconnect can pick up where they left off. It isn't part of the
+	// message itself, streams which support resuming send it alongside.
+	Seq uint64 `json:"-"`
 }
 
 type StreamMessage interface {
@@ -49,15 +54,93 @@ func (n NewPickMsg) GetType() string {
 // CurrentGame is a message that is sent to the client when it first connects
 // to the websocket, it contains the game id, the game times, and the picks
 // that have been made so far in the game. It is sent once per connection and
-// indicates to the client what the current game state is.
+// indicates to the client what the current game state is. ServerTime is when
+// the message was made, so clients can allow for their clocks being off.
 type CurrentGameMsg struct {
 	GameId               uint64 `json:"gameId"`
+	Phase                string `json:"phase"`
 	NextGameTime         int64  `json:"nextGameTime"`
 	CurrentGameStartTime int64  `json:"currentGameStartTime"`
 	CurrentGameEndTime   int64  `json:"currentGameEndTime"`
 	Picks                []int  `json:"picks"`
+	ServerTime           int64  `json:"serverTime"`
 }
 
 func (c CurrentGameMsg) GetType() string {
 	return "CUR"
 }
+
+// Win is a message that is sent only to the owner of a card when a game is
+// settled and the card won something on it. It contains the card id, the game
+// id, how many of the card's numbers were drawn and the prize.
+type WinMsg struct {
+	CardId  uint64 `json:"cardId"`
+	GameId  uint64 `json:"gameId"`
+	Matches int    `json:"matches"`
+	Prize   uint64 `json:"prize"`
+}
+
+func (w WinMsg) GetType() string {
+	return "WIN"
+}
+
+// Card is a message that is sent only to the owner of a card when it has been
+// placed, it contains the card id and ticket reference, the numbers selected, the games it covers
+// and what it cost.
+type CardMsg struct {
+	CardId    uint64 `json:"cardId"`
+	TicketRef string `json:"ticketRef"`
+	Selection []int  `json:"selection"`
+	StartGame uint64 `json:"startGame"`
+	LastGame  uint64 `json:"lastGame"`
+	TotalCost uint64 `json:"totalCost"`
+}
+
+func (c CardMsg) GetType() string {
+	return "CARD"
+}
+
+// Challenge is a message that is sent to both users in a challenge whenever it
+// changes, it contains the challenge id, the game it's on, its status and once
+// resolved who won the pot.
+type ChallengeMsg struct {
+	ChallengeId uint64 `json:"challengeId"`
+	GameId      uint64 `json:"gameId"`
+	Status      string `json:"status"`
+	Winner      string `json:"winner"`
+	Pot         uint64 `json:"pot"`
+}
+
+func (c ChallengeMsg) GetType() string {
+	return "CHL"
+}
+
+// Achievement is a message that is sent to every client when a user unlocks
+// an achievement, it contains the user, the achievement and the card and game
+// which unlocked it.
+type AchievementMsg struct {
+	User          string `json:"user"`
+	AchievementId string `json:"achievementId"`
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	CardId        uint64 `json:"cardId"`
+	GameId        uint64 `json:"gameId"`
+}
+
+func (a AchievementMsg) GetType() string {
+	return "ACH"
+}
+
+// Tournament is a message that is sent to everyone who entered a tournament
+// when it finishes, it contains the tournament id and their rank, score and
+// prize.
+type TournamentMsg struct {
+	TournamentId uint64 `json:"tournamentId"`
+	Rank         int    `json:"rank"`
+	Score        uint64 `json:"score"`
+	Prize        uint64 `json:"prize"`
+}
+
+func (t TournamentMsg) GetType() string {
+	return "TRN"
+}
@@ -0,0 +1,232 @@
+package models
+
+import (
+	"errors"
+	"keno/internal/utils"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Subscription statuses, only active subscriptions place cards.
+const (
+	SubscriptionActive       = "ACTIVE"
+	SubscriptionCancelled    = "CANCELLED"
+	SubscriptionBudgetSpent  = "BUDGET_SPENT"
+	SubscriptionThresholdHit = "THRESHOLD_HIT"
+)
+
+// ErrSubscriptionInactive is returned when trying to cancel a subscription
+// which has already stopped.
+var ErrSubscriptionInactive = errors.New("subscription is not active")
+
+// Subscription places a single game card with the same selection and stake on
+// every Every games, starting from NextGame. It stops when it's cancelled,
+// when another card would take Spent over Budget, or once Won reaches
+// WinThreshold. A Budget or WinThreshold of zero means there isn't one.
+type Subscription struct {
+	ID        uint64 `gorm:"primarykey"`
+	CreatedAt time.Time
+
+	Selection   []uint8 `json:"selection"`
+	SystemSpots uint8   `json:"system_spots"`
+	PerGame     uint64  `json:"per_game"`
+
+	Every        uint64 `json:"every"`
+	NextGame     uint64 `json:"next_game_num"`
+	Budget       uint64 `json:"budget"`
+	Spent        uint64 `json:"spent"`
+	WinThreshold uint64 `json:"win_threshold"`
+	Won          uint64 `json:"won"`
+	Status       string `json:"status"`
+
+	// CheckedGame is the first game whose cards haven't been added to Won
+	CheckedGame uint64 `json:"-"`
+
+	Tenant string `json:"-" gorm:"index;default:''"`
+	User   string `json:"user" gorm:"index"`
+}
+
+// Card returns the card the subscription places on gameNum.
+func (s Subscription) Card(gameNum uint64) Card {
+	selection := make([]uint8, len(s.Selection))
+	copy(selection, s.Selection)
+
+	return Card{
+		Selection:      selection,
+		StartGame:      gameNum,
+		LastGame:       gameNum + 1,
+		PerGame:        s.PerGame,
+		SystemSpots:    s.SystemSpots,
+		SubscriptionID: s.ID,
+		Tenant:         s.Tenant,
+		User:           s.User,
+	}
+}
+
+func GetSubscription(db *gorm.DB, id uint64) (*Subscription, error) {
+	var subscription Subscription
+	err := db.First(&subscription, id).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &subscription, nil
+}
+
+// GetSubscriptions returns every subscription the user has made, oldest first.
+func GetSubscriptions(db *gorm.DB, user string) ([]Subscription, error) {
+	var subscriptions []Subscription
+	err := db.Where("user = ?", user).Order("id").Find(&subscriptions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+// GetDueSubscriptions returns every active subscription which should place a
+// card on gameNum.
+func GetDueSubscriptions(db *gorm.DB, gameNum uint64) ([]Subscription, error) {
+	var subscriptions []Subscription
+	err := db.Where("status = ? AND next_game <= ?", SubscriptionActive, gameNum).
+		Order("id").
+		Find(&subscriptions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+func CreateSubscription(db *gorm.DB, subscription Subscription) (*Subscription, error) {
+	// Sort selection
+	selection := subscription.Selection
+	sort.Slice(selection, func(i, j int) bool { return selection[i] < selection[j] })
+
+	newSubscription := &subscription
+	newSubscription.ID = 0
+	newSubscription.CreatedAt = time.Now()
+	newSubscription.Status = SubscriptionActive
+	newSubscription.CheckedGame = subscription.NextGame
+
+	tx := db.Create(newSubscription)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	return newSubscription, nil
+}
+
+// UpdateSubscriptionWinnings adds the winnings of every card the subscription
+// placed on games before gameNum which haven't been counted yet to Won. All of
+// those games must have been drawn.
+func UpdateSubscriptionWinnings(db *gorm.DB, subscription *Subscription, gameNum uint64) error {
+	if subscription.CheckedGame >= gameNum {
+		return nil
+	}
+
+	var cards []Card
+	err := db.Where("subscription_id = ? AND start_game >= ? AND start_game < ?",
+		subscription.ID, subscription.CheckedGame, gameNum).Find(&cards).Error
+	if err != nil {
+		return err
+	}
+
+	won := subscription.Won
+	for _, card := range cards {
+		var ok bool
+		won, ok = utils.AddUint64(won, card.CheckCard(db))
+		if !ok {
+			return errors.New("subscription winnings overflowed")
+		}
+	}
+
+	tx := db.Model(subscription).Updates(map[string]interface{}{
+		"won":          won,
+		"checked_game": gameNum,
+	})
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	subscription.Won = won
+	subscription.CheckedGame = gameNum
+	return nil
+}
+
+// RecordRenewal moves the subscription on to its next game after it placed the
+// card, and logs the renewal to the user's history.
+func RecordRenewal(db *gorm.DB, subscription *Subscription, card *Card, cost uint64) error {
+	spent, ok := utils.AddUint64(subscription.Spent, cost)
+	if !ok {
+		return errors.New("subscription spend overflowed")
+	}
+
+	tx := db.Model(subscription).Updates(map[string]interface{}{
+		"spent":     spent,
+		"next_game": card.StartGame + subscription.Every,
+	})
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	subscription.Spent = spent
+	subscription.NextGame = card.StartGame + subscription.Every
+
+	return LogHistory(db, History{
+		Event:          HistorySubscriptionRenewed,
+		CardID:         card.ID,
+		SubscriptionID: subscription.ID,
+		GameID:         card.StartGame,
+		Amount:         cost,
+		Tenant:         subscription.Tenant,
+		User:           subscription.User,
+	})
+}
+
+// SkipRenewal moves the subscription on to its next game without placing a
+// card on gameNum, and logs why to the user's history.
+func SkipRenewal(db *gorm.DB, subscription *Subscription, gameNum uint64, reason string) error {
+	tx := db.Model(subscription).Update("next_game", gameNum+subscription.Every)
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	subscription.NextGame = gameNum + subscription.Every
+
+	return LogHistory(db, History{
+		Event:          HistorySubscriptionSkipped,
+		SubscriptionID: subscription.ID,
+		GameID:         gameNum,
+		Note:           reason,
+		Tenant:         subscription.Tenant,
+		User:           subscription.User,
+	})
+}
+
+// StopSubscription stops an active subscription with the given status and
+// logs it to the user's history. It returns ErrSubscriptionInactive if the
+// subscription had already stopped.
+func StopSubscription(db *gorm.DB, subscription *Subscription, status string) error {
+	tx := db.Model(&Subscription{}).
+		Where("id = ? AND status = ?", subscription.ID, SubscriptionActive).
+		Update("status", status)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return ErrSubscriptionInactive
+	}
+
+	subscription.Status = status
+
+	return LogHistory(db, History{
+		Event:          HistorySubscriptionStopped,
+		SubscriptionID: subscription.ID,
+		Note:           status,
+		Tenant:         subscription.Tenant,
+		User:           subscription.User,
+	})
+}
@@ -0,0 +1,298 @@
+package models
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Syndicate statuses, shares can only be bought while a syndicate is open. It
+// is placed as soon as every share has been sold.
+const (
+	SyndicateOpen      = "OPEN"
+	SyndicatePlaced    = "PLACED"
+	SyndicateCancelled = "CANCELLED"
+)
+
+var (
+	// ErrSyndicateClosed is returned when buying shares in or cancelling a
+	// syndicate which isn't open.
+	ErrSyndicateClosed = errors.New("syndicate is not open")
+
+	// ErrNotEnoughShares is returned when buying more shares than are left.
+	ErrNotEnoughShares = errors.New("not enough shares left in syndicate")
+)
+
+// Syndicate is a card bought between several users. The card's total cost is
+// split into Shares shares of SharePrice each, once they have all been sold the
+// card is placed and its winnings are split between the members by how many
+// shares they hold. User is whoever started the syndicate.
+type Syndicate struct {
+	ID        uint64 `gorm:"primarykey"`
+	CreatedAt time.Time
+
+	Name        string  `json:"name"`
+	Selection   []uint8 `json:"selection"`
+	SystemSpots uint8   `json:"system_spots"`
+	PerGame     uint64  `json:"per_game"`
+	NumGames    uint64  `json:"num_games"`
+
+	Shares     uint64 `json:"shares"`
+	SharePrice uint64 `json:"share_price"`
+	SharesSold uint64 `json:"shares_sold"`
+	Status     string `json:"status" gorm:"index"`
+
+	// CardID is set once the syndicate has been placed
+	CardID uint64 `json:"card_id"`
+
+	Tenant string `json:"-" gorm:"index;default:''"`
+	User   string `json:"user" gorm:"index"`
+}
+
+// SyndicateShare is a purchase of shares in a syndicate, a member can buy
+// shares more than once.
+type SyndicateShare struct {
+	ID        uint64 `gorm:"primarykey"`
+	CreatedAt time.Time
+
+	SyndicateID uint64 `json:"syndicate_id" gorm:"index"`
+	Shares      uint64 `json:"shares"`
+	Cost        uint64 `json:"cost"`
+
+	Tenant string `json:"-" gorm:"index;default:''"`
+	User   string `json:"user" gorm:"index"`
+}
+
+// SyndicatePayout is a member's part of what the syndicate's card won on a
+// game. There is only ever one payout for each member and game.
+type SyndicatePayout struct {
+	ID        uint64 `gorm:"primarykey"`
+	CreatedAt time.Time
+
+	SyndicateID uint64 `json:"syndicate_id" gorm:"uniqueIndex:idx_syndicate_payout"`
+	GameID      uint64 `json:"game_id" gorm:"uniqueIndex:idx_syndicate_payout"`
+	Shares      uint64 `json:"shares"`
+	Amount      uint64 `json:"amount"`
+
+	Tenant string `json:"-" gorm:"index;default:''"`
+	User   string `json:"user" gorm:"uniqueIndex:idx_syndicate_payout;index"`
+}
+
+// Card returns the card the syndicate places, starting on startGame.
+func (s Syndicate) Card(startGame uint64) Card {
+	selection := make([]uint8, len(s.Selection))
+	copy(selection, s.Selection)
+
+	return Card{
+		Selection:   selection,
+		StartGame:   startGame,
+		LastGame:    startGame + s.NumGames,
+		PerGame:     s.PerGame,
+		SystemSpots: s.SystemSpots,
+		SyndicateID: s.ID,
+		Tenant:      s.Tenant,
+		User:        s.User,
+	}
+}
+
+func GetSyndicate(db *gorm.DB, id uint64) (*Syndicate, error) {
+	var syndicate Syndicate
+	err := db.First(&syndicate, id).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &syndicate, nil
+}
+
+// GetOpenSyndicates returns every syndicate which still has shares for sale,
+// oldest first.
+func GetOpenSyndicates(db *gorm.DB) ([]Syndicate, error) {
+	var syndicates []Syndicate
+	err := db.Where("status = ?", SyndicateOpen).Order("id").Find(&syndicates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return syndicates, nil
+}
+
+// GetSyndicateShares returns every purchase of shares in the syndicate, in the
+// order they were bought.
+func GetSyndicateShares(db *gorm.DB, id uint64) ([]SyndicateShare, error) {
+	var shares []SyndicateShare
+	err := db.Where("syndicate_id = ?", id).Order("id").Find(&shares).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return shares, nil
+}
+
+// GetSyndicatePayouts returns every payout made by the syndicate, in game
+// order.
+func GetSyndicatePayouts(db *gorm.DB, id uint64) ([]SyndicatePayout, error) {
+	var payouts []SyndicatePayout
+	err := db.Where("syndicate_id = ?", id).Order("game_id, user").Find(&payouts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return payouts, nil
+}
+
+// CreateSyndicate commits a new open syndicate to the database. The syndicate
+// should have its card, shares and user set, everything else is filled in here.
+func CreateSyndicate(db *gorm.DB, syndicate Syndicate) (*Syndicate, error) {
+	// Sort selection
+	selection := syndicate.Selection
+	sort.Slice(selection, func(i, j int) bool { return selection[i] < selection[j] })
+
+	newSyndicate := &syndicate
+	newSyndicate.ID = 0
+	newSyndicate.CreatedAt = time.Now()
+	newSyndicate.SharesSold = 0
+	newSyndicate.Status = SyndicateOpen
+	newSyndicate.CardID = 0
+
+	tx := db.Create(newSyndicate)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	return newSyndicate, nil
+}
+
+// BuyShares sells the user shares in the syndicate. It returns
+// ErrSyndicateClosed if the syndicate isn't open and ErrNotEnoughShares if
+// there aren't that many left.
+func BuyShares(db *gorm.DB, syndicate *Syndicate, user string, shares uint64) (*SyndicateShare, error) {
+	// Only sell the shares if they're still there when the update happens
+	tx := db.Model(&Syndicate{}).
+		Where("id = ? AND status = ? AND shares_sold + ? <= shares", syndicate.ID, SyndicateOpen, shares).
+		Update("shares_sold", gorm.Expr("shares_sold + ?", shares))
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	if tx.RowsAffected == 0 {
+		current, err := GetSyndicate(db, syndicate.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		*syndicate = *current
+		if syndicate.Status != SyndicateOpen {
+			return nil, ErrSyndicateClosed
+		}
+
+		return nil, ErrNotEnoughShares
+	}
+
+	share := &SyndicateShare{
+		CreatedAt:   time.Now(),
+		SyndicateID: syndicate.ID,
+		Shares:      shares,
+		Cost:        shares * syndicate.SharePrice,
+		User:        user,
+	}
+
+	if err := db.Create(share).Error; err != nil {
+		return nil, err
+	}
+
+	syndicate.SharesSold += shares
+	return share, nil
+}
+
+// PlaceSyndicate records that the syndicate's card has been placed.
+func PlaceSyndicate(db *gorm.DB, syndicate *Syndicate, card *Card) error {
+	tx := db.Model(&Syndicate{}).
+		Where("id = ? AND status = ?", syndicate.ID, SyndicateOpen).
+		Updates(map[string]interface{}{"status": SyndicatePlaced, "card_id": card.ID})
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return ErrSyndicateClosed
+	}
+
+	syndicate.Status = SyndicatePlaced
+	syndicate.CardID = card.ID
+	return nil
+}
+
+// CancelSyndicate stops selling shares in an open syndicate, the shares already
+// sold are refunded. It returns ErrSyndicateClosed if the syndicate isn't open.
+func CancelSyndicate(db *gorm.DB, syndicate *Syndicate) error {
+	tx := db.Model(&Syndicate{}).
+		Where("id = ? AND status = ?", syndicate.ID, SyndicateOpen).
+		Update("status", SyndicateCancelled)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return ErrSyndicateClosed
+	}
+
+	syndicate.Status = SyndicateCancelled
+	return nil
+}
+
+// SplitPrize divides the result's prize between the syndicate's members by how
+// many shares they hold. Amounts are rounded down and whatever is left over
+// goes to the syndicate's creator.
+func SplitPrize(syndicate Syndicate, result CardResult, shares []SyndicateShare) []SyndicatePayout {
+	// Add up each member's shares, keeping the order they joined in
+	held := make(map[string]uint64)
+	members := make([]string, 0)
+	for _, share := range shares {
+		if _, ok := held[share.User]; !ok {
+			members = append(members, share.User)
+		}
+		held[share.User] += share.Shares
+	}
+
+	payouts := make([]SyndicatePayout, 0, len(members))
+	paid := uint64(0)
+	owner := -1
+	for _, member := range members {
+		// Shares are capped well below the point this could overflow
+		amount := result.Prize / syndicate.Shares * held[member]
+		amount += result.Prize % syndicate.Shares * held[member] / syndicate.Shares
+		paid += amount
+
+		if member == syndicate.User {
+			owner = len(payouts)
+		}
+
+		payouts = append(payouts, SyndicatePayout{
+			CreatedAt:   time.Now(),
+			SyndicateID: syndicate.ID,
+			GameID:      result.GameID,
+			Shares:      held[member],
+			Amount:      amount,
+			Tenant:      syndicate.Tenant,
+			User:        member,
+		})
+	}
+
+	if owner >= 0 {
+		payouts[owner].Amount += result.Prize - paid
+	}
+
+	return payouts
+}
+
+// SavePayouts commits the payouts to the database, skipping any which have
+// already been recorded so a game can safely be settled more than once.
+func SavePayouts(db *gorm.DB, payouts []SyndicatePayout) error {
+	if len(payouts) == 0 {
+		return nil
+	}
+
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&payouts).Error
+}
@@ -0,0 +1,69 @@
+package models
+
+import "testing"
+
+func TestSplitPrizeRemainderGoesToCreator(t *testing.T) {
+	syndicate := Syndicate{ID: 1, Shares: 3, User: "creator"}
+	shares := []SyndicateShare{
+		{User: "creator", Shares: 1},
+		{User: "member", Shares: 1},
+		{User: "other", Shares: 1},
+	}
+
+	payouts := SplitPrize(syndicate, CardResult{GameID: 1, Prize: 100}, shares)
+	if len(payouts) != 3 {
+		t.Fatalf("got %d payouts, want 3", len(payouts))
+	}
+
+	want := map[string]uint64{"creator": 34, "member": 33, "other": 33}
+	total := uint64(0)
+	for _, payout := range payouts {
+		if payout.Amount != want[payout.User] {
+			t.Errorf("%s was paid %d, want %d", payout.User, payout.Amount, want[payout.User])
+		}
+		total += payout.Amount
+	}
+	if total != 100 {
+		t.Errorf("paid out %d in total, want the whole prize of 100", total)
+	}
+}
+
+func TestSplitPrizeCombinesShares(t *testing.T) {
+	syndicate := Syndicate{ID: 1, Shares: 7, User: "creator"}
+	shares := []SyndicateShare{
+		{User: "creator", Shares: 2},
+		{User: "member", Shares: 3},
+		{User: "creator", Shares: 2},
+	}
+
+	payouts := SplitPrize(syndicate, CardResult{GameID: 1, Prize: 1000}, shares)
+	if len(payouts) != 2 {
+		t.Fatalf("got %d payouts, want 2", len(payouts))
+	}
+
+	// 1000 * 4 / 7 = 571 and 1000 * 3 / 7 = 428, leaving 1 over for the creator
+	want := map[string]uint64{"creator": 572, "member": 428}
+	for _, payout := range payouts {
+		if payout.Amount != want[payout.User] {
+			t.Errorf("%s was paid %d, want %d", payout.User, payout.Amount, want[payout.User])
+		}
+	}
+}
+
+func TestSplitPrizeWithoutCreator(t *testing.T) {
+	syndicate := Syndicate{ID: 1, Shares: 3, User: "creator"}
+	shares := []SyndicateShare{
+		{User: "member", Shares: 2},
+		{User: "other", Shares: 1},
+	}
+
+	payouts := SplitPrize(syndicate, CardResult{GameID: 1, Prize: 10}, shares)
+
+	// Nobody is given the remainder, so no one is paid more than their part
+	want := map[string]uint64{"member": 6, "other": 3}
+	for _, payout := range payouts {
+		if payout.Amount != want[payout.User] {
+			t.Errorf("%s was paid %d, want %d", payout.User, payout.Amount, want[payout.User])
+		}
+	}
+}
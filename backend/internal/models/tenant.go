@@ -0,0 +1,107 @@
+package models
+
+import (
+	"context"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// DefaultTenant is the tenant of requests made without a guild, and of every
+// row made before there were tenants.
+const DefaultTenant = ""
+
+type tenantKey struct{}
+
+// ForTenant returns a session which only sees the rows belonging to the
+// tenant, and gives every row it creates to the tenant. Only models with a
+// Tenant field are scoped, raw SQL isn't.
+func ForTenant(db *gorm.DB, tenant string) *gorm.DB {
+	return db.WithContext(context.WithValue(db.Statement.Context, tenantKey{}, tenant))
+}
+
+// AllTenants returns a session which sees the rows of every tenant, for
+// checks which follow a user wherever they play.
+func AllTenants(db *gorm.DB) *gorm.DB {
+	return db.WithContext(context.WithValue(db.Statement.Context, tenantKey{}, nil))
+}
+
+// GetTenant returns the tenant the session is scoped to, ok is false if it
+// sees every tenant.
+func GetTenant(db *gorm.DB) (tenant string, ok bool) {
+	if db.Statement.Context == nil {
+		return "", false
+	}
+
+	tenant, ok = db.Statement.Context.Value(tenantKey{}).(string)
+	return tenant, ok
+}
+
+// RegisterTenantScope adds the callbacks which scope sessions made with
+// ForTenant to their tenant.
+func RegisterTenantScope(db *gorm.DB) error {
+	callbacks := db.Callback()
+	if err := callbacks.Create().Before("gorm:create").Register("tenant:create", setTenant); err != nil {
+		return err
+	}
+	if err := callbacks.Query().Before("gorm:query").Register("tenant:query", scopeTenant); err != nil {
+		return err
+	}
+	if err := callbacks.Row().Before("gorm:row").Register("tenant:row", scopeTenant); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("gorm:update").Register("tenant:update", scopeTenant); err != nil {
+		return err
+	}
+
+	return callbacks.Delete().Before("gorm:delete").Register("tenant:delete", scopeTenant)
+}
+
+// tenantField returns the statement's Tenant field and the tenant its session
+// is scoped to, ok is false if either is missing.
+func tenantField(db *gorm.DB) (field *schema.Field, tenant string, ok bool) {
+	tenant, ok = GetTenant(db)
+	if !ok || db.Statement.Schema == nil {
+		return nil, "", false
+	}
+
+	field = db.Statement.Schema.LookUpField("Tenant")
+	return field, tenant, field != nil
+}
+
+// scopeTenant only lets the statement see rows belonging to the tenant.
+func scopeTenant(db *gorm.DB) {
+	field, tenant, ok := tenantField(db)
+	if !ok {
+		return
+	}
+
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: db.Statement.Table, Name: field.DBName}, Value: tenant},
+	}})
+}
+
+// setTenant gives every row being created to the tenant.
+func setTenant(db *gorm.DB) {
+	field, tenant, ok := tenantField(db)
+	if !ok {
+		return
+	}
+
+	value := db.Statement.ReflectValue
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := field.Set(db.Statement.Context, value.Index(i), tenant); err != nil {
+				db.AddError(err)
+				return
+			}
+		}
+	case reflect.Struct:
+		if err := field.Set(db.Statement.Context, value, tenant); err != nil {
+			db.AddError(err)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package models
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Ticket references are ticketIdBytes random bytes followed by the first
+	// ticketSigBytes of their HMAC, base32 encoded into 24 characters.
+	ticketIdBytes  = 10
+	ticketSigBytes = 5
+)
+
+var (
+	ticketSecret   []byte
+	ticketEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+)
+
+// Secret is a key the server made for itself, kept in the database so it
+// stays the same across restarts.
+type Secret struct {
+	Name  string `gorm:"primaryKey"`
+	Value []byte
+}
+
+// LoadTicketSecret sets the key ticket references are signed with. If secret
+// is empty the key is loaded from the database, and made the first time, so
+// references given out before a restart can still be verified after it.
+func LoadTicketSecret(db *gorm.DB, secret string) error {
+	if secret != "" {
+		ticketSecret = []byte(secret)
+		return nil
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return err
+	}
+
+	// Only the first key made is kept, whoever makes it
+	err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&Secret{Name: "ticket", Value: key}).Error
+	if err != nil {
+		return err
+	}
+
+	var stored Secret
+	if err := db.First(&stored, "name = ?", "ticket").Error; err != nil {
+		return err
+	}
+
+	log.WithField("src", "models.LoadTicketSecret").Info("No ticket secret set, using the stored one")
+	ticketSecret = stored.Value
+	return nil
+}
+
+// NewTicketRef returns a new random, signed ticket reference.
+func NewTicketRef() (string, error) {
+	id := make([]byte, ticketIdBytes)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+
+	return ticketEncoding.EncodeToString(append(id, signTicket(id)...)), nil
+}
+
+// VerifyTicketRef reports whether the reference was signed by this server,
+// forged or mistyped references fail.
+func VerifyTicketRef(ref string) bool {
+	data, err := ticketEncoding.DecodeString(strings.ToUpper(ref))
+	if err != nil || len(data) != ticketIdBytes+ticketSigBytes {
+		return false
+	}
+
+	return hmac.Equal(data[ticketIdBytes:], signTicket(data[:ticketIdBytes]))
+}
+
+func signTicket(id []byte) []byte {
+	mac := hmac.New(sha256.New, ticketSecret)
+	mac.Write(id)
+	return mac.Sum(nil)[:ticketSigBytes]
+}
+
+// GetCardByTicket returns the card with the ticket reference.
+func GetCardByTicket(db *gorm.DB, ref string) (*Card, error) {
+	var card Card
+	err := db.Where("ticket_ref = ?", strings.ToUpper(ref)).First(&card).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &card, nil
+}
+
+// BackfillTicketRefs gives a ticket reference to every card placed before they
+// existed.
+func BackfillTicketRefs(db *gorm.DB) error {
+	var cards []Card
+	return db.Where("ticket_ref = ? OR ticket_ref IS NULL", "").
+		FindInBatches(&cards, 500, func(tx *gorm.DB, _ int) error {
+			for _, card := range cards {
+				ref, err := NewTicketRef()
+				if err != nil {
+					return err
+				}
+
+				err = tx.Model(&Card{}).Where("id = ?", card.ID).Update("ticket_ref", ref).Error
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}).Error
+}
@@ -0,0 +1,240 @@
+package models
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Tournament statuses, a tournament is open until its last game has been
+// settled and its prizes paid.
+const (
+	TournamentOpen     = "OPEN"
+	TournamentFinished = "FINISHED"
+)
+
+var (
+	// ErrAlreadyRegistered is returned when registering for a tournament twice.
+	ErrAlreadyRegistered = errors.New("already registered for tournament")
+
+	// ErrBankrollSpent is returned when a tournament card costs more than is
+	// left of the entry's bankroll.
+	ErrBankrollSpent = errors.New("not enough tournament bankroll left")
+)
+
+// Tournament is a competition over the games from FirstGame up to, but not
+// including, LastGame. Everyone who registers gets the same Bankroll to place
+// tournament cards with, which is kept apart from their own money. Entries are
+// scored by how much their tournament cards win, and once the last game has
+// been settled the entry ranked n-th is paid Prizes[n-1].
+type Tournament struct {
+	ID        uint64 `gorm:"primarykey"`
+	CreatedAt time.Time
+
+	Name      string   `json:"name"`
+	FirstGame uint64   `json:"first_game_num"`
+	LastGame  uint64   `json:"last_game_num" gorm:"index"`
+	Bankroll  uint64   `json:"bankroll"`
+	Prizes    []uint64 `json:"prizes" gorm:"serializer:json"`
+	Status    string   `json:"status"`
+
+	Tenant string `json:"-" gorm:"index;default:''"`
+}
+
+// TournamentEntry is a user's registration for a tournament. Balance is what's
+// left of their bankroll and Score is everything their tournament cards have
+// won so far. Rank and Prize are set when the tournament finishes.
+type TournamentEntry struct {
+	ID        uint64 `gorm:"primarykey"`
+	CreatedAt time.Time
+
+	TournamentID uint64 `json:"tournament_id" gorm:"uniqueIndex:idx_tournament_entry"`
+	Balance      uint64 `json:"balance"`
+	Score        uint64 `json:"score"`
+	Rank         int    `json:"rank"`
+	Prize        uint64 `json:"prize"`
+
+	Tenant string `json:"-" gorm:"index;default:''"`
+	User   string `json:"user" gorm:"uniqueIndex:idx_tournament_entry;index"`
+}
+
+func GetTournament(db *gorm.DB, id uint64) (*Tournament, error) {
+	var tournament Tournament
+	err := db.First(&tournament, id).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &tournament, nil
+}
+
+// GetTournaments returns every tournament, latest first.
+func GetTournaments(db *gorm.DB) ([]Tournament, error) {
+	var tournaments []Tournament
+	err := db.Order("first_game DESC, id DESC").Find(&tournaments).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return tournaments, nil
+}
+
+// CreateTournament commits a new open tournament to the database.
+func CreateTournament(db *gorm.DB, tournament Tournament) (*Tournament, error) {
+	newTournament := &tournament
+	newTournament.ID = 0
+	newTournament.CreatedAt = time.Now()
+	newTournament.Status = TournamentOpen
+
+	tx := db.Create(newTournament)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	return newTournament, nil
+}
+
+// GetTournamentEntry returns the user's entry in the tournament.
+func GetTournamentEntry(db *gorm.DB, tournamentId uint64, user string) (*TournamentEntry, error) {
+	var entry TournamentEntry
+	err := db.Where("tournament_id = ? AND user = ?", tournamentId, user).First(&entry).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// GetStandings returns every entry in the tournament ranked by score. Entries
+// with the same score are ranked by who has more bankroll left, then by who
+// registered first.
+func GetStandings(db *gorm.DB, tournamentId uint64) ([]TournamentEntry, error) {
+	var entries []TournamentEntry
+	err := db.Where("tournament_id = ?", tournamentId).
+		Order("score DESC, balance DESC, id").
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range entries {
+		if entries[i].Rank == 0 {
+			entries[i].Rank = i + 1
+		}
+	}
+
+	return entries, nil
+}
+
+// RegisterForTournament enters the user into the tournament with a full
+// bankroll. It returns ErrAlreadyRegistered if they have already entered.
+func RegisterForTournament(db *gorm.DB, tournament *Tournament, user string) (*TournamentEntry, error) {
+	entry := &TournamentEntry{
+		CreatedAt:    time.Now(),
+		TournamentID: tournament.ID,
+		Balance:      tournament.Bankroll,
+		User:         user,
+	}
+
+	// Only one entry is kept per user, even when they register twice at once
+	tx := db.Clauses(clause.OnConflict{DoNothing: true}).Create(entry)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return nil, ErrAlreadyRegistered
+	}
+
+	return entry, nil
+}
+
+// SpendBankroll takes cost from the entry's balance. It returns
+// ErrBankrollSpent if there isn't that much left.
+func SpendBankroll(db *gorm.DB, entry *TournamentEntry, cost uint64) error {
+	tx := db.Model(&TournamentEntry{}).
+		Where("id = ? AND balance >= ?", entry.ID, cost).
+		Update("balance", gorm.Expr("balance - ?", cost))
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return ErrBankrollSpent
+	}
+
+	entry.Balance -= cost
+	return nil
+}
+
+// GetGameTournaments returns the IDs of every tournament with cards on the
+// settled results.
+func GetGameTournaments(results []CardResult) []uint64 {
+	seen := make(map[uint64]bool)
+	ids := make([]uint64, 0)
+	for _, result := range results {
+		if result.TournamentID != 0 && !seen[result.TournamentID] {
+			seen[result.TournamentID] = true
+			ids = append(ids, result.TournamentID)
+		}
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// UpdateTournamentScores works out every entry's score again from the settled
+// results of their tournament cards, so it's safe to run more than once.
+func UpdateTournamentScores(db *gorm.DB, tournamentId uint64) error {
+	return db.Exec(`UPDATE tournament_entries SET score = (
+		SELECT COALESCE(SUM(prize), 0) FROM card_results
+		WHERE card_results.tournament_id = tournament_entries.tournament_id
+		AND card_results.user = tournament_entries.user
+	) WHERE tournament_id = ?`, tournamentId).Error
+}
+
+// FinishTournament ranks the entries, pays the prizes and marks the tournament
+// finished. It returns the entries in rank order, or nil if the tournament had
+// already finished.
+func FinishTournament(db *gorm.DB, tournament *Tournament) ([]TournamentEntry, error) {
+	var standings []TournamentEntry
+	err := db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&Tournament{}).
+			Where("id = ? AND status = ?", tournament.ID, TournamentOpen).
+			Update("status", TournamentFinished)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+
+		entries, err := GetStandings(tx, tournament.ID)
+		if err != nil {
+			return err
+		}
+
+		for i := range entries {
+			entries[i].Rank = i + 1
+			if i < len(tournament.Prizes) {
+				entries[i].Prize = tournament.Prizes[i]
+			}
+
+			err := tx.Model(&TournamentEntry{}).Where("id = ?", entries[i].ID).
+				Updates(map[string]interface{}{"rank": entries[i].Rank, "prize": entries[i].Prize}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		standings = entries
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tournament.Status = TournamentFinished
+	return standings, nil
+}
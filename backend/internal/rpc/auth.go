@@ -0,0 +1,104 @@
+package rpc
+
+import (
+	"context"
+	"keno/internal/api"
+	"keno/kenopb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Metadata keys calls are authenticated with, the same as the REST headers.
+const (
+	AUTHORIZATION_KEY = "authorization"
+	GUILD_KEY         = "x-guild-id"
+)
+
+// publicMethods can be called without authorization, like the public REST
+// endpoints and the websocket.
+var publicMethods = []string{
+	kenopb.Keno_GetGame_FullMethodName,
+	kenopb.Keno_ListGames_FullMethodName,
+	kenopb.Keno_WatchGames_FullMethodName,
+}
+
+type userKey struct{}
+
+// user is who a call was authenticated as and the tenant it's made in.
+type user struct {
+	id     string
+	tenant string
+}
+
+// getUser returns the user the call was authenticated as, public calls get
+// the empty user in the default community.
+func getUser(ctx context.Context) user {
+	u, _ := ctx.Value(userKey{}).(user)
+	return u
+}
+
+// authenticate checks the Discord token and guild in the call's metadata, the
+// same way as api.DiscordAuth, and adds the user to the context.
+func authenticate(ctx context.Context, method string) (context.Context, error) {
+	for _, public := range publicMethods {
+		if method == public {
+			return ctx, nil
+		}
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	authToken := firstValue(md, AUTHORIZATION_KEY)
+	guild := firstValue(md, GUILD_KEY)
+
+	if authToken == "" {
+		return nil, toStatus(api.ErrInvalidToken)
+	}
+
+	userId, tenant, err := api.Authenticate(authToken, guild)
+	if err != nil {
+		log.WithField("src", "rpc.authenticate").WithError(err).Error("Call made with an invalid token or guild")
+		return nil, toStatus(err)
+	}
+
+	return context.WithValue(ctx, userKey{}, user{id: userId, tenant: tenant}), nil
+}
+
+// firstValue returns the first value for the key in the metadata, or "" if
+// there isn't one.
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func authUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func authStream(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := authenticate(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, &authedStream{ServerStream: stream, ctx: ctx})
+}
+
+// authedStream is a stream with the authenticated user in its context
+type authedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authedStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,103 @@
+package rpc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"keno/internal/api"
+	"keno/internal/models"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// IDEMPOTENCY_KEY is the metadata key which makes a call safe to retry, the
+// same as the REST Idempotency-Key header.
+const IDEMPOTENCY_KEY = "idempotency-key"
+
+// idempotent runs handle for the call unless it has an idempotency key which
+// the user has already used, in which case resp is filled in with the response
+// to the first call instead. Calls which fail release the key again, as they
+// never change anything. Calls without the key are handled as normal.
+func (s *Server) idempotent(ctx context.Context, method string, req, resp proto.Message, handle func() error) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	key := firstValue(md, IDEMPOTENCY_KEY)
+	if key == "" {
+		return handle()
+	}
+
+	if len(key) > api.MaxIdempotencyKeyLength {
+		return toStatus(api.ErrInvalidIdempotencyKey)
+	}
+
+	// Hash the request so reusing the key for something else can be caught
+	body, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return toStatus(err)
+	}
+	hash := sha256.New()
+	hash.Write([]byte(method + "\n"))
+	hash.Write(body)
+	requestHash := hex.EncodeToString(hash.Sum(nil))
+
+	db := s.tenantDB(ctx)
+	now := time.Now()
+	record, err := models.ReserveIdempotencyKey(db, getUser(ctx).id, key, requestHash,
+		now.Add(-api.IdempotencyRetention), now.Add(-api.IdempotencyTimeout))
+	if errors.Is(err, models.ErrIdempotencyKeyUsed) {
+		switch {
+		case record.RequestHash != requestHash:
+			return toStatus(api.ErrIdempotencyKeyReused)
+		case record.Status == 0:
+			return toStatus(api.ErrIdempotencyKeyInProgress)
+		}
+
+		if err := proto.Unmarshal(record.Body, resp); err != nil {
+			log.WithField("src", "rpc.idempotent").WithError(err).Error("Error reading idempotent response")
+			return toStatus(err)
+		}
+		if err := grpc.SetHeader(ctx, metadata.Pairs("idempotent-replayed", "true")); err != nil {
+			log.WithField("src", "rpc.idempotent").WithError(err).Warn("Error setting replayed header")
+		}
+		return nil
+	}
+	if err != nil {
+		log.WithField("src", "rpc.idempotent").WithError(err).Error("Error reserving idempotency key")
+		return toStatus(err)
+	}
+
+	// Release the key on the way out unless the response was stored, so a
+	// failed or panicking call doesn't leave it in progress
+	stored := false
+	defer func() {
+		if stored {
+			return
+		}
+
+		if err := models.ReleaseIdempotencyKey(db, record.ID); err != nil {
+			log.WithField("src", "rpc.idempotent").WithError(err).Error("Error releasing idempotency key")
+		}
+	}()
+
+	if err := handle(); err != nil {
+		return err
+	}
+
+	body, err = proto.Marshal(resp)
+	if err == nil {
+		// The status only marks the record complete, the body is the response
+		err = models.CompleteIdempotencyKey(db, record.ID, http.StatusOK, body)
+	}
+	if err != nil {
+		log.WithField("src", "rpc.idempotent").WithError(err).Error("Error storing idempotent response")
+	}
+	stored = err == nil
+
+	return nil
+}
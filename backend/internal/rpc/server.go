@@ -0,0 +1,287 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"keno/internal/api"
+	"keno/internal/engine"
+	"keno/internal/models"
+	"keno/kenopb"
+	"math"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/runtime/protoiface"
+	"gorm.io/gorm"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrorDomain is the domain of the ErrorInfo detail attached to every error,
+// its reason is the APIError code.
+const ErrorDomain = "keno"
+
+// Server is the gRPC API. It offers the same operations as the REST API and
+// goes through the same logic in the api package, so both follow the same
+// rules.
+type Server struct {
+	kenopb.UnimplementedKenoServer
+
+	db     *gorm.DB
+	engine *engine.Engine
+}
+
+// NewServer returns a gRPC server with the Keno service registered, calls are
+// authenticated from their metadata before they reach it. Any options, such as
+// the TLS credentials, are added to the server's own.
+func NewServer(db *gorm.DB, gameEngine *engine.Engine, opts ...grpc.ServerOption) *grpc.Server {
+	server := grpc.NewServer(append([]grpc.ServerOption{
+		grpc.UnaryInterceptor(authUnary),
+		grpc.StreamInterceptor(authStream),
+	}, opts...)...)
+	kenopb.RegisterKenoServer(server, &Server{db: db, engine: gameEngine})
+
+	return server
+}
+
+func (s *Server) PlaceCard(ctx context.Context, req *kenopb.PlaceCardRequest) (*kenopb.Card, error) {
+	card := &kenopb.Card{}
+	err := s.idempotent(ctx, kenopb.Keno_PlaceCard_FullMethodName, req, card, func() error {
+		picks := make([]uint8, 0, len(req.Picks))
+		for _, num := range req.Picks {
+			picks = append(picks, toUint8(num))
+		}
+
+		user := getUser(ctx)
+		resp, err := api.PlaceCard(s.tenantDB(ctx), s.engine, user.id, api.PickRequest{
+			PicksPerGame: toUint8(req.PicksPerGame),
+			Picks:        picks,
+			PricePerGame: req.PricePerGame,
+			NumGames:     toUint8(req.NumberGames),
+			SystemSize:   toUint8(req.SystemSize),
+			QuickPick:    req.QuickPick,
+		})
+		if err != nil {
+			log.WithField("src", "rpc.PlaceCard").WithError(err).Error("Error placing card")
+			return toStatus(err)
+		}
+
+		card.CardId = resp.CardId
+		card.TicketRef = resp.TicketRef
+		card.Selection = toUint32s(resp.Selection)
+		card.StartGameNum = resp.StartGame
+		card.LastGameNum = resp.LastGame
+		card.StartTime = resp.StartTime
+		card.Combinations = resp.Combinations
+		card.TotalCost = resp.TotalCost
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return card, nil
+}
+
+func (s *Server) CheckCard(ctx context.Context, req *kenopb.CheckCardRequest) (*kenopb.CheckCardResponse, error) {
+	resp, err := api.CheckCardWinnings(s.tenantDB(ctx), s.engine, req.CardId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	games := make([]*kenopb.CardGameResult, 0, len(resp.Games))
+	for _, game := range resp.Games {
+		games = append(games, &kenopb.CardGameResult{
+			GameId:  game.GameId,
+			Matches: uint32(game.Matches),
+			Prize:   game.Prize,
+		})
+	}
+
+	return &kenopb.CheckCardResponse{Amount: resp.Amount, Games: games}, nil
+}
+
+func (s *Server) GetGame(ctx context.Context, req *kenopb.GetGameRequest) (*kenopb.Game, error) {
+	resp, err := api.LookupGame(s.db, s.engine, req.GameId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return gameToProto(*resp), nil
+}
+
+func (s *Server) ListGames(ctx context.Context, req *kenopb.ListGamesRequest) (*kenopb.ListGamesResponse, error) {
+	resp, err := api.LookupGames(s.db, s.engine, api.GamesRequest{
+		Page:     int(req.Page),
+		PerPage:  int(req.PerPage),
+		FromGame: req.FromGame,
+		ToGame:   req.ToGame,
+		Since:    req.Since,
+		Until:    req.Until,
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	games := make([]*kenopb.Game, 0, len(resp.Games))
+	for _, game := range resp.Games {
+		games = append(games, gameToProto(game))
+	}
+
+	return &kenopb.ListGamesResponse{
+		Games:   games,
+		Page:    int32(resp.Page),
+		PerPage: int32(resp.PerPage),
+		Total:   resp.Total,
+	}, nil
+}
+
+func (s *Server) WatchGames(req *kenopb.WatchGamesRequest, stream kenopb.Keno_WatchGamesServer) error {
+	// Add the listener, with room for every message which could be replayed
+	listener := make(chan models.Message, engine.ReplaySize+10)
+	if req.LastEventId > 0 {
+		s.engine.AddListenerAfter(listener, req.LastEventId)
+	} else {
+		s.engine.AddListener(listener)
+	}
+	defer s.engine.RemoveListener(listener)
+
+	for {
+		select {
+		case message := <-listener:
+			event := messageToEvent(message)
+			if event == nil {
+				continue
+			}
+
+			if err := stream.Send(event); err != nil {
+				log.WithField("src", "rpc.WatchGames").WithError(err).Error("Error sending game event")
+				return err
+			}
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+// tenantDB returns the database scoped to the tenant the call is made in.
+func (s *Server) tenantDB(ctx context.Context) *gorm.DB {
+	return models.ForTenant(s.db, getUser(ctx).tenant)
+}
+
+// toStatus turns an error from the api package into a gRPC status, with the
+// APIError code attached as the reason of an ErrorInfo so clients can act on
+// it, and its details as the field violations of a BadRequest. Anything which
+// isn't an APIError is an internal error.
+func toStatus(err error) error {
+	var apiErr api.APIError
+	if !errors.As(err, &apiErr) {
+		apiErr = api.ErrInternalError
+	}
+
+	code := codes.InvalidArgument
+	switch {
+	case errors.Is(apiErr, api.ErrInternalError):
+		code = codes.Internal
+	case errors.Is(apiErr, api.ErrInvalidToken):
+		code = codes.Unauthenticated
+	case errors.Is(apiErr, api.ErrInvalidGuild), api.IsLimitError(apiErr):
+		code = codes.PermissionDenied
+	case errors.Is(apiErr, api.ErrInvalidCard), errors.Is(apiErr, api.ErrInvalidGame):
+		code = codes.NotFound
+	case errors.Is(apiErr, api.ErrUnfinishedGames):
+		code = codes.FailedPrecondition
+	case errors.Is(apiErr, api.ErrLiabilityExceeded):
+		code = codes.ResourceExhausted
+	case errors.Is(apiErr, api.ErrIdempotencyKeyReused):
+		code = codes.AlreadyExists
+	case errors.Is(apiErr, api.ErrIdempotencyKeyInProgress):
+		code = codes.Aborted
+	}
+
+	details := []protoiface.MessageV1{&errdetails.ErrorInfo{
+		Reason: apiErr.Code,
+		Domain: ErrorDomain,
+	}}
+
+	if len(apiErr.Details) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, detail := range apiErr.Details {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       detail.Field,
+				Description: detail.Code + ": " + detail.Message,
+			})
+		}
+		details = append(details, badRequest)
+	}
+
+	st, detailErr := status.New(code, apiErr.Message).WithDetails(details...)
+	if detailErr != nil {
+		return status.Error(code, apiErr.Message)
+	}
+
+	return st.Err()
+}
+
+// messageToEvent returns the game event for a stream message, or nil for
+// messages which aren't about the game.
+func messageToEvent(message models.Message) *kenopb.GameEvent {
+	event := &kenopb.GameEvent{Id: message.Seq}
+
+	switch body := message.Body.(type) {
+	case models.NewGameMsg:
+		event.Event = &kenopb.GameEvent_NewGame{NewGame: &kenopb.NewGame{
+			GameId:               body.GameId,
+			NextGameTime:         body.NextGameTime,
+			CurrentGameStartTime: body.CurrentGameStartTime,
+			CurrentGameEndTime:   body.CurrentGameEndTime,
+		}}
+	case models.NewPickMsg:
+		event.Event = &kenopb.GameEvent_NewPick{NewPick: &kenopb.NewPick{
+			Pick: uint32(body.Pick),
+		}}
+	case models.CurrentGameMsg:
+		event.Event = &kenopb.GameEvent_CurrentGame{CurrentGame: &kenopb.CurrentGame{
+			GameId:               body.GameId,
+			Phase:                body.Phase,
+			NextGameTime:         body.NextGameTime,
+			CurrentGameStartTime: body.CurrentGameStartTime,
+			CurrentGameEndTime:   body.CurrentGameEndTime,
+			Picks:                toUint32s(body.Picks),
+			ServerTime:           body.ServerTime,
+		}}
+	default:
+		return nil
+	}
+
+	return event
+}
+
+func gameToProto(game api.GameResponse) *kenopb.Game {
+	return &kenopb.Game{
+		GameId:    game.GameId,
+		Picks:     toUint32s(game.Picks),
+		StartTime: game.StartTime,
+		EndTime:   game.EndTime,
+		Status:    game.Status,
+	}
+}
+
+// toUint8 converts a number from a request, numbers too big for a uint8 are
+// capped at the largest one so the pick rules still reject them.
+func toUint8(a uint32) uint8 {
+	if a > math.MaxUint8 {
+		return math.MaxUint8
+	}
+	return uint8(a)
+}
+
+func toUint32s(s []int) []uint32 {
+	nums := make([]uint32, 0, len(s))
+	for _, a := range s {
+		nums = append(nums, uint32(a))
+	}
+	return nums
+}
@@ -0,0 +1,256 @@
+package settlement
+
+import (
+	"errors"
+	"fmt"
+	"keno/internal/models"
+	"keno/internal/utils"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// BatchSize is how many cards are loaded and settled at a time
+	BatchSize = 500
+
+	// Failed settlements are retried a few times in quick succession, then
+	// every RetryLater until they succeed. Games are settled in order, so the
+	// games after a failed one wait for it.
+	Retries    = 3
+	RetryDelay = 5 * time.Second
+	RetryLater = time.Minute
+)
+
+// SettledHook is run with every result of a game once they have all been
+// recorded. A hook which returns an error is run again with the same results
+// when the game is retried, and a crash can make any hook run again, so
+// hooks have to be safe to repeat.
+type SettledHook func(game models.Game, results []models.CardResult) error
+
+// namedHook is a settled hook with the name its progress is recorded under
+type namedHook struct {
+	name string
+	fn   SettledHook
+}
+
+// Worker settles each game as the engine completes it, recording a result for
+// every card covering the game and then running the settled hooks. Games are
+// settled one at a time in the order they were drawn, and a game is only marked
+// settled once every hook has run on it. Games which were abandoned part way
+// through their draw are settled too, their results refund each card's stake.
+type Worker struct {
+	db *gorm.DB
+
+	// queue holds the games which have completed but haven't been settled,
+	// queued signals that there's something on it
+	queueMu sync.Mutex
+	queue   []models.Game
+	queued  chan struct{}
+
+	mu    sync.RWMutex
+	hooks []namedHook
+}
+
+func NewWorker(db *gorm.DB) *Worker {
+	return &Worker{
+		db:     db,
+		queue:  make([]models.Game, 0),
+		queued: make(chan struct{}, 1),
+		hooks:  make([]namedHook, 0),
+	}
+}
+
+// AddSettledHook registers fn to be run on every result of each game once it
+// has been settled. Which hooks have run on each game is recorded under their
+// name, so the name has to stay the same across restarts.
+func (w *Worker) AddSettledHook(name string, fn SettledHook) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.hooks = append(w.hooks, namedHook{name: name, fn: fn})
+}
+
+// GameComplete queues the game to be settled, it is meant to be registered as
+// a game complete hook on the engine. It never blocks, so the game loop isn't
+// held up however far behind settlement is.
+func (w *Worker) GameComplete(game models.Game) {
+	w.queueMu.Lock()
+	w.queue = append(w.queue, game)
+	w.queueMu.Unlock()
+
+	select {
+	case w.queued <- struct{}{}:
+	default:
+	}
+}
+
+// Start settles any games before firstGame left over from before a restart,
+// then settles each game as it is queued. firstGame should be the first game
+// the engine will draw. It blocks, so run it in its own goroutine.
+func (w *Worker) Start(firstGame uint64) {
+	games, err := models.GetUnsettledGames(w.db, firstGame)
+	if err != nil {
+		log.WithField("src", "settlement.Start").WithError(err).Error("Error getting unsettled games")
+	}
+
+	for _, game := range games {
+		w.settle(game)
+	}
+
+	for range w.queued {
+		for {
+			game, ok := w.next()
+			if !ok {
+				break
+			}
+
+			w.settle(game)
+		}
+	}
+}
+
+// next takes the oldest game off the queue, ok is false if it's empty.
+func (w *Worker) next() (game models.Game, ok bool) {
+	w.queueMu.Lock()
+	defer w.queueMu.Unlock()
+
+	if len(w.queue) == 0 {
+		return models.Game{}, false
+	}
+
+	game = w.queue[0]
+	w.queue = w.queue[1:]
+	return game, true
+}
+
+// settle settles the game, retrying until it succeeds.
+func (w *Worker) settle(game models.Game) {
+	for attempt := 1; ; attempt++ {
+		err := w.SettleGame(&game)
+		if err == nil {
+			return
+		}
+
+		log.WithFields(log.Fields{
+			"src":     "settlement.settle",
+			"game":    game.ID,
+			"attempt": attempt,
+		}).WithError(err).Error("Error settling game")
+
+		if attempt < Retries {
+			time.Sleep(RetryDelay)
+		} else {
+			time.Sleep(RetryLater)
+		}
+	}
+}
+
+// SettleGame records a result for every card covering the game, runs each
+// settled hook which hasn't already run on the game with all of its results,
+// then marks the game as settled. It is safe to run again on a game which was
+// part way through being settled, only the missing results are recorded and
+// only the hooks which didn't finish are run.
+func (w *Worker) SettleGame(game *models.Game) error {
+	recorded, err := RecordResults(w.db, game)
+	if err != nil {
+		return err
+	}
+
+	done, err := models.GetSettledHooks(w.db, game.ID)
+	if err != nil {
+		return err
+	}
+
+	w.mu.RLock()
+	hooks := append([]namedHook{}, w.hooks...)
+	w.mu.RUnlock()
+
+	var results []models.CardResult
+	var failed error
+	for _, hook := range hooks {
+		if utils.Contains(done, hook.name) {
+			continue
+		}
+
+		// Only load the results once a hook needs them
+		if results == nil {
+			results, err = models.GetGameResults(w.db, game.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := hook.fn(*game, results); err != nil {
+			failed = errors.Join(failed, fmt.Errorf("%s hook: %w", hook.name, err))
+			continue
+		}
+
+		if err := models.MarkHookDone(w.db, game.ID, hook.name); err != nil {
+			failed = errors.Join(failed, err)
+		}
+	}
+	if failed != nil {
+		return failed
+	}
+
+	if err := models.MarkGameSettled(w.db, game.ID); err != nil {
+		return err
+	}
+
+	// No more cards can be placed on the game, so its liability isn't needed
+	if err := models.PruneGameLiabilities(w.db, game.ID); err != nil {
+		log.WithField("src", "settlement.SettleGame").WithError(err).Warn("Error pruning game liabilities")
+	}
+
+	log.WithFields(log.Fields{
+		"src":   "settlement.SettleGame",
+		"game":  game.ID,
+		"cards": len(recorded),
+	}).Info("Game Settled")
+
+	return nil
+}
+
+// RecordResults records a result for every card covering the game. Results
+// which were already recorded are left alone, so it is safe to run again on a
+// game which was part way through being settled. It returns the results which
+// were recorded this time.
+func RecordResults(db *gorm.DB, game *models.Game) ([]models.CardResult, error) {
+	results := make([]models.CardResult, 0)
+
+	var cards []models.Card
+	tx := db.Where("start_game <= ? AND last_game > ?", game.ID, game.ID).
+		FindInBatches(&cards, BatchSize, func(_ *gorm.DB, _ int) error {
+			batch := make([]models.CardResult, 0, len(cards))
+			for _, card := range cards {
+				result, ok := card.Result(game)
+				if !ok {
+					log.WithFields(log.Fields{
+						"src":  "settlement.RecordResults",
+						"card": card.ID,
+						"game": game.ID,
+					}).Error("Card winnings overflowed")
+					continue
+				}
+
+				batch = append(batch, result)
+			}
+
+			saved, err := models.SaveResults(db, game.ID, batch)
+			if err != nil {
+				return err
+			}
+
+			results = append(results, saved...)
+			return nil
+		})
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	return results, nil
+}
@@ -0,0 +1,225 @@
+package settlement
+
+import (
+	"errors"
+	"keno/internal/db"
+	"keno/internal/models"
+	"path/filepath"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+var drawn = []uint8{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20}
+
+func setup(t *testing.T, picks []uint8, cards int) (*gorm.DB, *models.Game) {
+	t.Helper()
+
+	database, err := db.SetupDatabase(filepath.Join(t.TempDir(), "keno.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	game := &models.Game{ID: 1, Picks: picks}
+	if err := models.CommitNewGame(database, game); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < cards; i++ {
+		_, err := models.SubmitCard(database, models.Card{
+			Selection: []uint8{1, 2, 30},
+			StartGame: 1,
+			LastGame:  2,
+			PerGame:   5,
+			User:      "user",
+		}, models.LiabilityCaps{})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return database, game
+}
+
+func countResults(t *testing.T, database *gorm.DB) int64 {
+	t.Helper()
+
+	var count int64
+	if err := database.Model(&models.CardResult{}).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	return count
+}
+
+func getGame(t *testing.T, database *gorm.DB, id uint64) *models.Game {
+	t.Helper()
+
+	game, err := models.GetGame(database, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return game
+}
+
+func TestRecordResultsIsIdempotent(t *testing.T) {
+	database, game := setup(t, drawn, 3)
+
+	results, err := RecordResults(database, game)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("first settlement returned %d results, want 3", len(results))
+	}
+
+	results, err = RecordResults(database, game)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 0 {
+		t.Errorf("second settlement returned %d results, want 0", len(results))
+	}
+
+	if count := countResults(t, database); count != 3 {
+		t.Errorf("%d results were recorded, want 3", count)
+	}
+}
+
+func TestRecordResultsPartialRerun(t *testing.T) {
+	database, game := setup(t, drawn, 3)
+
+	// Settlement stopped after recording the first card's result
+	card, err := models.GetCard(database, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, _ := card.Result(game)
+	if _, err := models.SaveResults(database, game.ID, []models.CardResult{result}); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := RecordResults(database, game)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("re-run returned %d results, want 2", len(results))
+	}
+	for _, result := range results {
+		if result.CardID == card.ID {
+			t.Errorf("re-run returned the result for card %d again", card.ID)
+		}
+	}
+
+	if count := countResults(t, database); count != 3 {
+		t.Errorf("%d results were recorded, want 3", count)
+	}
+}
+
+func TestSettleGameRunsHooksAfterCrash(t *testing.T) {
+	database, game := setup(t, drawn, 3)
+
+	// Settlement stopped after recording every result but before any hook ran
+	if _, err := RecordResults(database, game); err != nil {
+		t.Fatal(err)
+	}
+
+	worker := NewWorker(database)
+	var got []models.CardResult
+	worker.AddSettledHook("test", func(_ models.Game, results []models.CardResult) error {
+		got = append(got, results...)
+		return nil
+	})
+
+	if err := worker.SettleGame(game); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 {
+		t.Errorf("hook was run with %d results, want all 3", len(got))
+	}
+	if !getGame(t, database, game.ID).Settled {
+		t.Error("game wasn't marked settled")
+	}
+
+	// Settling it again doesn't run the hook again
+	if err := worker.SettleGame(game); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 {
+		t.Errorf("hook was run again, %d results seen in total", len(got))
+	}
+}
+
+func TestSettleGameRetriesFailedHooks(t *testing.T) {
+	database, game := setup(t, drawn, 2)
+
+	worker := NewWorker(database)
+	runs := map[string]int{}
+	worker.AddSettledHook("ok", func(models.Game, []models.CardResult) error {
+		runs["ok"]++
+		return nil
+	})
+	worker.AddSettledHook("flaky", func(_ models.Game, results []models.CardResult) error {
+		runs["flaky"]++
+		if runs["flaky"] == 1 {
+			return errors.New("failed")
+		}
+		if len(results) != 2 {
+			t.Errorf("retried hook was run with %d results, want 2", len(results))
+		}
+		return nil
+	})
+
+	if err := worker.SettleGame(game); err == nil {
+		t.Fatal("settling with a failed hook didn't return an error")
+	}
+	if getGame(t, database, game.ID).Settled {
+		t.Fatal("game was marked settled while a hook had failed")
+	}
+
+	if err := worker.SettleGame(game); err != nil {
+		t.Fatal(err)
+	}
+	if runs["ok"] != 1 || runs["flaky"] != 2 {
+		t.Errorf("hooks were run %v times, want ok once and flaky twice", runs)
+	}
+	if !getGame(t, database, game.ID).Settled {
+		t.Error("game wasn't marked settled")
+	}
+}
+
+func TestSettleAbandonedGameRefunds(t *testing.T) {
+	database, game := setup(t, drawn[:7], 1)
+
+	results, err := RecordResults(database, game)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("settlement returned %d results, want 1", len(results))
+	}
+
+	result := results[0]
+	if result.Refund != result.Stake || result.Prize != 0 || result.Matches != 0 {
+		t.Errorf("got refund %d, prize %d and %d matches for a stake of %d, want the stake refunded",
+			result.Refund, result.Prize, result.Matches, result.Stake)
+	}
+}
+
+func TestGameCompleteDoesNotBlock(t *testing.T) {
+	worker := NewWorker(nil)
+
+	// Nothing is settling the games, queueing them still has to return
+	for i := uint64(1); i <= 100; i++ {
+		worker.GameComplete(models.Game{ID: i})
+	}
+
+	for i := uint64(1); i <= 100; i++ {
+		game, ok := worker.next()
+		if !ok || game.ID != i {
+			t.Fatalf("got game %d from the queue, want %d", game.ID, i)
+		}
+	}
+}
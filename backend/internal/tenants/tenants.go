@@ -0,0 +1,62 @@
+package tenants
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Tenant is a Discord guild sharing the backend. Everything its members do in
+// the guild, their cards, results, leaderboards and so on, is kept apart from
+// every other guild. Tenants are loaded from a config file so guilds can be
+// added without touching the code.
+//
+// The backend has no wallets or rooms, stakes aren't taken from a balance and
+// every guild watches the same draw, so there are none to keep apart yet.
+// Giving each guild its own wallets and rooms is left for a follow-up request,
+// which will need to add both before they can be scoped.
+type Tenant struct {
+	GuildID string `json:"guild_id"`
+	Name    string `json:"name"`
+
+	// MaxGameLiability caps how much the guild's cards can win between them
+	// on a single game, on top of the cap on every guild's cards together.
+	// Zero means only that cap applies.
+	MaxGameLiability uint64 `json:"max_game_liability,omitempty"`
+
+	// MaxStake caps the cost of a single card placed in the guild, on top of
+	// any limit the user has set, zero means there isn't a cap
+	MaxStake uint64 `json:"max_stake,omitempty"`
+}
+
+// LoadTenants reads the tenants from the JSON config file at path, keyed by
+// their guild ID. A missing file means there are no tenants, and only the
+// default community can use the backend.
+func LoadTenants(path string) (map[string]Tenant, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		log.WithField("src", "tenants.LoadTenants").Warn("No tenants config found")
+		return map[string]Tenant{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var list []Tenant
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+
+	// Everything is stored against the guild ID, so it has to be unique
+	tenants := make(map[string]Tenant, len(list))
+	for _, tenant := range list {
+		if _, ok := tenants[tenant.GuildID]; ok || tenant.GuildID == "" {
+			return nil, fmt.Errorf("guild ID %q is empty or repeated", tenant.GuildID)
+		}
+		tenants[tenant.GuildID] = tenant
+	}
+
+	return tenants, nil
+}
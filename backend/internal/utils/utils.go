@@ -1,6 +1,11 @@
 package utils
 
-func Contains(s []uint8, e uint8) bool {
+import (
+	"math"
+	"math/bits"
+)
+
+func Contains[T comparable](s []T, e T) bool {
 	for _, a := range s {
 		if a == e {
 			return true
@@ -8,3 +13,85 @@ func Contains(s []uint8, e uint8) bool {
 	}
 	return false
 }
+
+// ToInts converts numbers to ints, so they are encoded as a JSON array rather
+// than a base64 string.
+func ToInts(s []uint8) []int {
+	ints := make([]int, 0, len(s))
+	for _, a := range s {
+		ints = append(ints, int(a))
+	}
+	return ints
+}
+
+// MulUint64 multiplies a and b, ok is false if the result overflowed.
+func MulUint64(a, b uint64) (uint64, bool) {
+	hi, lo := bits.Mul64(a, b)
+	return lo, hi == 0
+}
+
+// AddUint64 adds a and b, ok is false if the result overflowed.
+func AddUint64(a, b uint64) (uint64, bool) {
+	sum, carry := bits.Add64(a, b, 0)
+	return sum, carry == 0
+}
+
+// Combinations returns the number of ways to choose k items from n, saturating
+// at math.MaxUint64 if there are too many to count.
+func Combinations(n, k int) uint64 {
+	if k < 0 || k > n {
+		return 0
+	}
+
+	if k > n-k {
+		k = n - k
+	}
+
+	// Each step turns C(n-k+i-1, i-1) into C(n-k+i, i), which divides exactly
+	result := uint64(1)
+	for i := 1; i <= k; i++ {
+		product, ok := MulUint64(result, uint64(n-k+i))
+		if !ok {
+			return math.MaxUint64
+		}
+
+		result = product / uint64(i)
+	}
+
+	return result
+}
+
+// ForEachCombination calls fn with every k sized combination of s, in order.
+// The slice passed to fn is reused between calls, so copy it to keep it.
+func ForEachCombination(s []uint8, k int, fn func(combo []uint8)) {
+	if k < 0 || k > len(s) {
+		return
+	}
+
+	indexes := make([]int, k)
+	for i := range indexes {
+		indexes[i] = i
+	}
+
+	combo := make([]uint8, k)
+	for {
+		for i, index := range indexes {
+			combo[i] = s[index]
+		}
+		fn(combo)
+
+		// Find the right most index which can still move forward
+		i := k - 1
+		for i >= 0 && indexes[i] == len(s)-k+i {
+			i--
+		}
+		if i < 0 {
+			return
+		}
+
+		indexes[i]++
+		for j := i + 1; j < k; j++ {
+			indexes[j] = indexes[j-1] + 1
+		}
+	}
+}
@@ -0,0 +1,6 @@
+// Package kenopb holds the gRPC API's protobuf messages and service, which
+// are generated from keno.proto. Clients in other languages can generate
+// their own from the same file.
+package kenopb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative keno.proto
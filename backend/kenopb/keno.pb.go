@@ -0,0 +1,1437 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.30.0
+// 	protoc        (unknown)
+// source: keno.proto
+
+package kenopb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type PlaceCardRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PicksPerGame uint32   `protobuf:"varint,1,opt,name=picks_per_game,json=picksPerGame,proto3" json:"picks_per_game,omitempty"`
+	Picks        []uint32 `protobuf:"varint,2,rep,packed,name=picks,proto3" json:"picks,omitempty"`
+	PricePerGame uint64   `protobuf:"varint,3,opt,name=price_per_game,json=pricePerGame,proto3" json:"price_per_game,omitempty"`
+	NumberGames  uint32   `protobuf:"varint,4,opt,name=number_games,json=numberGames,proto3" json:"number_games,omitempty"`
+	// system_size turns the card into a system entry, picks holds system_size
+	// numbers and every picks_per_game sized combination of them is played.
+	SystemSize uint32 `protobuf:"varint,5,opt,name=system_size,json=systemSize,proto3" json:"system_size,omitempty"`
+	// quick_pick asks the server to pick the numbers, picks is ignored.
+	QuickPick bool `protobuf:"varint,6,opt,name=quick_pick,json=quickPick,proto3" json:"quick_pick,omitempty"`
+}
+
+func (x *PlaceCardRequest) Reset() {
+	*x = PlaceCardRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_keno_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PlaceCardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlaceCardRequest) ProtoMessage() {}
+
+func (x *PlaceCardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_keno_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlaceCardRequest.ProtoReflect.Descriptor instead.
+func (*PlaceCardRequest) Descriptor() ([]byte, []int) {
+	return file_keno_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *PlaceCardRequest) GetPicksPerGame() uint32 {
+	if x != nil {
+		return x.PicksPerGame
+	}
+	return 0
+}
+
+func (x *PlaceCardRequest) GetPicks() []uint32 {
+	if x != nil {
+		return x.Picks
+	}
+	return nil
+}
+
+func (x *PlaceCardRequest) GetPricePerGame() uint64 {
+	if x != nil {
+		return x.PricePerGame
+	}
+	return 0
+}
+
+func (x *PlaceCardRequest) GetNumberGames() uint32 {
+	if x != nil {
+		return x.NumberGames
+	}
+	return 0
+}
+
+func (x *PlaceCardRequest) GetSystemSize() uint32 {
+	if x != nil {
+		return x.SystemSize
+	}
+	return 0
+}
+
+func (x *PlaceCardRequest) GetQuickPick() bool {
+	if x != nil {
+		return x.QuickPick
+	}
+	return false
+}
+
+type Card struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	CardId       uint64   `protobuf:"varint,1,opt,name=card_id,json=cardId,proto3" json:"card_id,omitempty"`
+	TicketRef    string   `protobuf:"bytes,2,opt,name=ticket_ref,json=ticketRef,proto3" json:"ticket_ref,omitempty"`
+	Selection    []uint32 `protobuf:"varint,3,rep,packed,name=selection,proto3" json:"selection,omitempty"`
+	StartGameNum uint64   `protobuf:"varint,4,opt,name=start_game_num,json=startGameNum,proto3" json:"start_game_num,omitempty"`
+	LastGameNum  uint64   `protobuf:"varint,5,opt,name=last_game_num,json=lastGameNum,proto3" json:"last_game_num,omitempty"`
+	// start_time is when the first game is due to start, in unix milliseconds.
+	StartTime    int64  `protobuf:"varint,6,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	Combinations uint64 `protobuf:"varint,7,opt,name=combinations,proto3" json:"combinations,omitempty"`
+	TotalCost    uint64 `protobuf:"varint,8,opt,name=total_cost,json=totalCost,proto3" json:"total_cost,omitempty"`
+}
+
+func (x *Card) Reset() {
+	*x = Card{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_keno_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Card) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Card) ProtoMessage() {}
+
+func (x *Card) ProtoReflect() protoreflect.Message {
+	mi := &file_keno_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Card.ProtoReflect.Descriptor instead.
+func (*Card) Descriptor() ([]byte, []int) {
+	return file_keno_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Card) GetCardId() uint64 {
+	if x != nil {
+		return x.CardId
+	}
+	return 0
+}
+
+func (x *Card) GetTicketRef() string {
+	if x != nil {
+		return x.TicketRef
+	}
+	return ""
+}
+
+func (x *Card) GetSelection() []uint32 {
+	if x != nil {
+		return x.Selection
+	}
+	return nil
+}
+
+func (x *Card) GetStartGameNum() uint64 {
+	if x != nil {
+		return x.StartGameNum
+	}
+	return 0
+}
+
+func (x *Card) GetLastGameNum() uint64 {
+	if x != nil {
+		return x.LastGameNum
+	}
+	return 0
+}
+
+func (x *Card) GetStartTime() int64 {
+	if x != nil {
+		return x.StartTime
+	}
+	return 0
+}
+
+func (x *Card) GetCombinations() uint64 {
+	if x != nil {
+		return x.Combinations
+	}
+	return 0
+}
+
+func (x *Card) GetTotalCost() uint64 {
+	if x != nil {
+		return x.TotalCost
+	}
+	return 0
+}
+
+type CheckCardRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	CardId uint64 `protobuf:"varint,1,opt,name=card_id,json=cardId,proto3" json:"card_id,omitempty"`
+}
+
+func (x *CheckCardRequest) Reset() {
+	*x = CheckCardRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_keno_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CheckCardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckCardRequest) ProtoMessage() {}
+
+func (x *CheckCardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_keno_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckCardRequest.ProtoReflect.Descriptor instead.
+func (*CheckCardRequest) Descriptor() ([]byte, []int) {
+	return file_keno_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CheckCardRequest) GetCardId() uint64 {
+	if x != nil {
+		return x.CardId
+	}
+	return 0
+}
+
+type CheckCardResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Amount uint64            `protobuf:"varint,1,opt,name=amount,proto3" json:"amount,omitempty"`
+	Games  []*CardGameResult `protobuf:"bytes,2,rep,name=games,proto3" json:"games,omitempty"`
+}
+
+func (x *CheckCardResponse) Reset() {
+	*x = CheckCardResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_keno_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CheckCardResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckCardResponse) ProtoMessage() {}
+
+func (x *CheckCardResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_keno_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckCardResponse.ProtoReflect.Descriptor instead.
+func (*CheckCardResponse) Descriptor() ([]byte, []int) {
+	return file_keno_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CheckCardResponse) GetAmount() uint64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *CheckCardResponse) GetGames() []*CardGameResult {
+	if x != nil {
+		return x.Games
+	}
+	return nil
+}
+
+type CardGameResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	GameId  uint64 `protobuf:"varint,1,opt,name=game_id,json=gameId,proto3" json:"game_id,omitempty"`
+	Matches uint32 `protobuf:"varint,2,opt,name=matches,proto3" json:"matches,omitempty"`
+	Prize   uint64 `protobuf:"varint,3,opt,name=prize,proto3" json:"prize,omitempty"`
+}
+
+func (x *CardGameResult) Reset() {
+	*x = CardGameResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_keno_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CardGameResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CardGameResult) ProtoMessage() {}
+
+func (x *CardGameResult) ProtoReflect() protoreflect.Message {
+	mi := &file_keno_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CardGameResult.ProtoReflect.Descriptor instead.
+func (*CardGameResult) Descriptor() ([]byte, []int) {
+	return file_keno_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CardGameResult) GetGameId() uint64 {
+	if x != nil {
+		return x.GameId
+	}
+	return 0
+}
+
+func (x *CardGameResult) GetMatches() uint32 {
+	if x != nil {
+		return x.Matches
+	}
+	return 0
+}
+
+func (x *CardGameResult) GetPrize() uint64 {
+	if x != nil {
+		return x.Prize
+	}
+	return 0
+}
+
+type GetGameRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	GameId uint64 `protobuf:"varint,1,opt,name=game_id,json=gameId,proto3" json:"game_id,omitempty"`
+}
+
+func (x *GetGameRequest) Reset() {
+	*x = GetGameRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_keno_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetGameRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetGameRequest) ProtoMessage() {}
+
+func (x *GetGameRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_keno_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetGameRequest.ProtoReflect.Descriptor instead.
+func (*GetGameRequest) Descriptor() ([]byte, []int) {
+	return file_keno_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetGameRequest) GetGameId() uint64 {
+	if x != nil {
+		return x.GameId
+	}
+	return 0
+}
+
+type Game struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	GameId uint64   `protobuf:"varint,1,opt,name=game_id,json=gameId,proto3" json:"game_id,omitempty"`
+	Picks  []uint32 `protobuf:"varint,2,rep,packed,name=picks,proto3" json:"picks,omitempty"`
+	// Times are in unix milliseconds, and are zero for games from before they
+	// were recorded.
+	StartTime int64 `protobuf:"varint,3,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime   int64 `protobuf:"varint,4,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	// status is DRAWING, COMPLETE or ABANDONED.
+	Status string `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+}
+
+func (x *Game) Reset() {
+	*x = Game{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_keno_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Game) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Game) ProtoMessage() {}
+
+func (x *Game) ProtoReflect() protoreflect.Message {
+	mi := &file_keno_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Game.ProtoReflect.Descriptor instead.
+func (*Game) Descriptor() ([]byte, []int) {
+	return file_keno_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Game) GetGameId() uint64 {
+	if x != nil {
+		return x.GameId
+	}
+	return 0
+}
+
+func (x *Game) GetPicks() []uint32 {
+	if x != nil {
+		return x.Picks
+	}
+	return nil
+}
+
+func (x *Game) GetStartTime() int64 {
+	if x != nil {
+		return x.StartTime
+	}
+	return 0
+}
+
+func (x *Game) GetEndTime() int64 {
+	if x != nil {
+		return x.EndTime
+	}
+	return 0
+}
+
+func (x *Game) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type ListGamesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// page starts from 1, and per_page can be up to 100. Zero uses the default.
+	Page    int32 `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
+	PerPage int32 `protobuf:"varint,2,opt,name=per_page,json=perPage,proto3" json:"per_page,omitempty"`
+	// Games can be narrowed down to a range of game numbers, and to those which
+	// started within a range of unix millisecond times. Zero means no limit.
+	FromGame uint64 `protobuf:"varint,3,opt,name=from_game,json=fromGame,proto3" json:"from_game,omitempty"`
+	ToGame   uint64 `protobuf:"varint,4,opt,name=to_game,json=toGame,proto3" json:"to_game,omitempty"`
+	Since    int64  `protobuf:"varint,5,opt,name=since,proto3" json:"since,omitempty"`
+	Until    int64  `protobuf:"varint,6,opt,name=until,proto3" json:"until,omitempty"`
+}
+
+func (x *ListGamesRequest) Reset() {
+	*x = ListGamesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_keno_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListGamesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListGamesRequest) ProtoMessage() {}
+
+func (x *ListGamesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_keno_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListGamesRequest.ProtoReflect.Descriptor instead.
+func (*ListGamesRequest) Descriptor() ([]byte, []int) {
+	return file_keno_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListGamesRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListGamesRequest) GetPerPage() int32 {
+	if x != nil {
+		return x.PerPage
+	}
+	return 0
+}
+
+func (x *ListGamesRequest) GetFromGame() uint64 {
+	if x != nil {
+		return x.FromGame
+	}
+	return 0
+}
+
+func (x *ListGamesRequest) GetToGame() uint64 {
+	if x != nil {
+		return x.ToGame
+	}
+	return 0
+}
+
+func (x *ListGamesRequest) GetSince() int64 {
+	if x != nil {
+		return x.Since
+	}
+	return 0
+}
+
+func (x *ListGamesRequest) GetUntil() int64 {
+	if x != nil {
+		return x.Until
+	}
+	return 0
+}
+
+type ListGamesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Games   []*Game `protobuf:"bytes,1,rep,name=games,proto3" json:"games,omitempty"`
+	Page    int32   `protobuf:"varint,2,opt,name=page,proto3" json:"page,omitempty"`
+	PerPage int32   `protobuf:"varint,3,opt,name=per_page,json=perPage,proto3" json:"per_page,omitempty"`
+	Total   int64   `protobuf:"varint,4,opt,name=total,proto3" json:"total,omitempty"`
+}
+
+func (x *ListGamesResponse) Reset() {
+	*x = ListGamesResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_keno_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListGamesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListGamesResponse) ProtoMessage() {}
+
+func (x *ListGamesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_keno_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListGamesResponse.ProtoReflect.Descriptor instead.
+func (*ListGamesResponse) Descriptor() ([]byte, []int) {
+	return file_keno_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListGamesResponse) GetGames() []*Game {
+	if x != nil {
+		return x.Games
+	}
+	return nil
+}
+
+func (x *ListGamesResponse) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListGamesResponse) GetPerPage() int32 {
+	if x != nil {
+		return x.PerPage
+	}
+	return 0
+}
+
+func (x *ListGamesResponse) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type WatchGamesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// last_event_id resumes a stream from after the event with that id, the
+	// events missed are sent if they are recent enough, otherwise the stream
+	// starts with the current game like a new one.
+	LastEventId uint64 `protobuf:"varint,1,opt,name=last_event_id,json=lastEventId,proto3" json:"last_event_id,omitempty"`
+}
+
+func (x *WatchGamesRequest) Reset() {
+	*x = WatchGamesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_keno_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchGamesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchGamesRequest) ProtoMessage() {}
+
+func (x *WatchGamesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_keno_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchGamesRequest.ProtoReflect.Descriptor instead.
+func (*WatchGamesRequest) Descriptor() ([]byte, []int) {
+	return file_keno_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *WatchGamesRequest) GetLastEventId() uint64 {
+	if x != nil {
+		return x.LastEventId
+	}
+	return 0
+}
+
+// GameEvent carries the same messages as the websocket, NEW, PIC and CUR. Its
+// id numbers the events so a stream can be resumed.
+type GameEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id uint64 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Types that are assignable to Event:
+	//	*GameEvent_NewGame
+	//	*GameEvent_NewPick
+	//	*GameEvent_CurrentGame
+	Event isGameEvent_Event `protobuf_oneof:"event"`
+}
+
+func (x *GameEvent) Reset() {
+	*x = GameEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_keno_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GameEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GameEvent) ProtoMessage() {}
+
+func (x *GameEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_keno_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GameEvent.ProtoReflect.Descriptor instead.
+func (*GameEvent) Descriptor() ([]byte, []int) {
+	return file_keno_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GameEvent) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (m *GameEvent) GetEvent() isGameEvent_Event {
+	if m != nil {
+		return m.Event
+	}
+	return nil
+}
+
+func (x *GameEvent) GetNewGame() *NewGame {
+	if x, ok := x.GetEvent().(*GameEvent_NewGame); ok {
+		return x.NewGame
+	}
+	return nil
+}
+
+func (x *GameEvent) GetNewPick() *NewPick {
+	if x, ok := x.GetEvent().(*GameEvent_NewPick); ok {
+		return x.NewPick
+	}
+	return nil
+}
+
+func (x *GameEvent) GetCurrentGame() *CurrentGame {
+	if x, ok := x.GetEvent().(*GameEvent_CurrentGame); ok {
+		return x.CurrentGame
+	}
+	return nil
+}
+
+type isGameEvent_Event interface {
+	isGameEvent_Event()
+}
+
+type GameEvent_NewGame struct {
+	NewGame *NewGame `protobuf:"bytes,2,opt,name=new_game,json=newGame,proto3,oneof"`
+}
+
+type GameEvent_NewPick struct {
+	NewPick *NewPick `protobuf:"bytes,3,opt,name=new_pick,json=newPick,proto3,oneof"`
+}
+
+type GameEvent_CurrentGame struct {
+	CurrentGame *CurrentGame `protobuf:"bytes,4,opt,name=current_game,json=currentGame,proto3,oneof"`
+}
+
+func (*GameEvent_NewGame) isGameEvent_Event() {}
+
+func (*GameEvent_NewPick) isGameEvent_Event() {}
+
+func (*GameEvent_CurrentGame) isGameEvent_Event() {}
+
+type NewGame struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	GameId               uint64 `protobuf:"varint,1,opt,name=game_id,json=gameId,proto3" json:"game_id,omitempty"`
+	NextGameTime         int64  `protobuf:"varint,2,opt,name=next_game_time,json=nextGameTime,proto3" json:"next_game_time,omitempty"`
+	CurrentGameStartTime int64  `protobuf:"varint,3,opt,name=current_game_start_time,json=currentGameStartTime,proto3" json:"current_game_start_time,omitempty"`
+	CurrentGameEndTime   int64  `protobuf:"varint,4,opt,name=current_game_end_time,json=currentGameEndTime,proto3" json:"current_game_end_time,omitempty"`
+}
+
+func (x *NewGame) Reset() {
+	*x = NewGame{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_keno_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NewGame) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NewGame) ProtoMessage() {}
+
+func (x *NewGame) ProtoReflect() protoreflect.Message {
+	mi := &file_keno_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NewGame.ProtoReflect.Descriptor instead.
+func (*NewGame) Descriptor() ([]byte, []int) {
+	return file_keno_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *NewGame) GetGameId() uint64 {
+	if x != nil {
+		return x.GameId
+	}
+	return 0
+}
+
+func (x *NewGame) GetNextGameTime() int64 {
+	if x != nil {
+		return x.NextGameTime
+	}
+	return 0
+}
+
+func (x *NewGame) GetCurrentGameStartTime() int64 {
+	if x != nil {
+		return x.CurrentGameStartTime
+	}
+	return 0
+}
+
+func (x *NewGame) GetCurrentGameEndTime() int64 {
+	if x != nil {
+		return x.CurrentGameEndTime
+	}
+	return 0
+}
+
+type NewPick struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Pick uint32 `protobuf:"varint,1,opt,name=pick,proto3" json:"pick,omitempty"`
+}
+
+func (x *NewPick) Reset() {
+	*x = NewPick{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_keno_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NewPick) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NewPick) ProtoMessage() {}
+
+func (x *NewPick) ProtoReflect() protoreflect.Message {
+	mi := &file_keno_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NewPick.ProtoReflect.Descriptor instead.
+func (*NewPick) Descriptor() ([]byte, []int) {
+	return file_keno_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *NewPick) GetPick() uint32 {
+	if x != nil {
+		return x.Pick
+	}
+	return 0
+}
+
+type CurrentGame struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	GameId uint64 `protobuf:"varint,1,opt,name=game_id,json=gameId,proto3" json:"game_id,omitempty"`
+	// phase is BETTING or DRAWING.
+	Phase                string   `protobuf:"bytes,2,opt,name=phase,proto3" json:"phase,omitempty"`
+	NextGameTime         int64    `protobuf:"varint,3,opt,name=next_game_time,json=nextGameTime,proto3" json:"next_game_time,omitempty"`
+	CurrentGameStartTime int64    `protobuf:"varint,4,opt,name=current_game_start_time,json=currentGameStartTime,proto3" json:"current_game_start_time,omitempty"`
+	CurrentGameEndTime   int64    `protobuf:"varint,5,opt,name=current_game_end_time,json=currentGameEndTime,proto3" json:"current_game_end_time,omitempty"`
+	Picks                []uint32 `protobuf:"varint,6,rep,packed,name=picks,proto3" json:"picks,omitempty"`
+	ServerTime           int64    `protobuf:"varint,7,opt,name=server_time,json=serverTime,proto3" json:"server_time,omitempty"`
+}
+
+func (x *CurrentGame) Reset() {
+	*x = CurrentGame{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_keno_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CurrentGame) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CurrentGame) ProtoMessage() {}
+
+func (x *CurrentGame) ProtoReflect() protoreflect.Message {
+	mi := &file_keno_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CurrentGame.ProtoReflect.Descriptor instead.
+func (*CurrentGame) Descriptor() ([]byte, []int) {
+	return file_keno_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *CurrentGame) GetGameId() uint64 {
+	if x != nil {
+		return x.GameId
+	}
+	return 0
+}
+
+func (x *CurrentGame) GetPhase() string {
+	if x != nil {
+		return x.Phase
+	}
+	return ""
+}
+
+func (x *CurrentGame) GetNextGameTime() int64 {
+	if x != nil {
+		return x.NextGameTime
+	}
+	return 0
+}
+
+func (x *CurrentGame) GetCurrentGameStartTime() int64 {
+	if x != nil {
+		return x.CurrentGameStartTime
+	}
+	return 0
+}
+
+func (x *CurrentGame) GetCurrentGameEndTime() int64 {
+	if x != nil {
+		return x.CurrentGameEndTime
+	}
+	return 0
+}
+
+func (x *CurrentGame) GetPicks() []uint32 {
+	if x != nil {
+		return x.Picks
+	}
+	return nil
+}
+
+func (x *CurrentGame) GetServerTime() int64 {
+	if x != nil {
+		return x.ServerTime
+	}
+	return 0
+}
+
+var File_keno_proto protoreflect.FileDescriptor
+
+var file_keno_proto_rawDesc = []byte{
+	0x0a, 0x0a, 0x6b, 0x65, 0x6e, 0x6f, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x07, 0x6b, 0x65,
+	0x6e, 0x6f, 0x2e, 0x76, 0x31, 0x22, 0xd7, 0x01, 0x0a, 0x10, 0x50, 0x6c, 0x61, 0x63, 0x65, 0x43,
+	0x61, 0x72, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x24, 0x0a, 0x0e, 0x70, 0x69,
+	0x63, 0x6b, 0x73, 0x5f, 0x70, 0x65, 0x72, 0x5f, 0x67, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x0c, 0x70, 0x69, 0x63, 0x6b, 0x73, 0x50, 0x65, 0x72, 0x47, 0x61, 0x6d, 0x65,
+	0x12, 0x14, 0x0a, 0x05, 0x70, 0x69, 0x63, 0x6b, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0d, 0x52,
+	0x05, 0x70, 0x69, 0x63, 0x6b, 0x73, 0x12, 0x24, 0x0a, 0x0e, 0x70, 0x72, 0x69, 0x63, 0x65, 0x5f,
+	0x70, 0x65, 0x72, 0x5f, 0x67, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c,
+	0x70, 0x72, 0x69, 0x63, 0x65, 0x50, 0x65, 0x72, 0x47, 0x61, 0x6d, 0x65, 0x12, 0x21, 0x0a, 0x0c,
+	0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x5f, 0x67, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x0b, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x47, 0x61, 0x6d, 0x65, 0x73, 0x12,
+	0x1f, 0x0a, 0x0b, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x53, 0x69, 0x7a, 0x65,
+	0x12, 0x1d, 0x0a, 0x0a, 0x71, 0x75, 0x69, 0x63, 0x6b, 0x5f, 0x70, 0x69, 0x63, 0x6b, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x71, 0x75, 0x69, 0x63, 0x6b, 0x50, 0x69, 0x63, 0x6b, 0x22,
+	0x88, 0x02, 0x0a, 0x04, 0x43, 0x61, 0x72, 0x64, 0x12, 0x17, 0x0a, 0x07, 0x63, 0x61, 0x72, 0x64,
+	0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x63, 0x61, 0x72, 0x64, 0x49,
+	0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x74, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x5f, 0x72, 0x65, 0x66, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x74, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x52, 0x65, 0x66,
+	0x12, 0x1c, 0x0a, 0x09, 0x73, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20,
+	0x03, 0x28, 0x0d, 0x52, 0x09, 0x73, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x24,
+	0x0a, 0x0e, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x67, 0x61, 0x6d, 0x65, 0x5f, 0x6e, 0x75, 0x6d,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c, 0x73, 0x74, 0x61, 0x72, 0x74, 0x47, 0x61, 0x6d,
+	0x65, 0x4e, 0x75, 0x6d, 0x12, 0x22, 0x0a, 0x0d, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x67, 0x61, 0x6d,
+	0x65, 0x5f, 0x6e, 0x75, 0x6d, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x6c, 0x61, 0x73,
+	0x74, 0x47, 0x61, 0x6d, 0x65, 0x4e, 0x75, 0x6d, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x72,
+	0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x73, 0x74,
+	0x61, 0x72, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x22, 0x0a, 0x0c, 0x63, 0x6f, 0x6d, 0x62, 0x69,
+	0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c, 0x63,
+	0x6f, 0x6d, 0x62, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x74,
+	0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x63, 0x6f, 0x73, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x09, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x43, 0x6f, 0x73, 0x74, 0x22, 0x2b, 0x0a, 0x10, 0x43, 0x68,
+	0x65, 0x63, 0x6b, 0x43, 0x61, 0x72, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x17,
+	0x0a, 0x07, 0x63, 0x61, 0x72, 0x64, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x06, 0x63, 0x61, 0x72, 0x64, 0x49, 0x64, 0x22, 0x5a, 0x0a, 0x11, 0x43, 0x68, 0x65, 0x63, 0x6b,
+	0x43, 0x61, 0x72, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06,
+	0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x61, 0x6d,
+	0x6f, 0x75, 0x6e, 0x74, 0x12, 0x2d, 0x0a, 0x05, 0x67, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x6b, 0x65, 0x6e, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61,
+	0x72, 0x64, 0x47, 0x61, 0x6d, 0x65, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x05, 0x67, 0x61,
+	0x6d, 0x65, 0x73, 0x22, 0x59, 0x0a, 0x0e, 0x43, 0x61, 0x72, 0x64, 0x47, 0x61, 0x6d, 0x65, 0x52,
+	0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x67, 0x61, 0x6d, 0x65, 0x5f, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x67, 0x61, 0x6d, 0x65, 0x49, 0x64, 0x12, 0x18,
+	0x0a, 0x07, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x07, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x72, 0x69, 0x7a,
+	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x70, 0x72, 0x69, 0x7a, 0x65, 0x22, 0x29,
+	0x0a, 0x0e, 0x47, 0x65, 0x74, 0x47, 0x61, 0x6d, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x17, 0x0a, 0x07, 0x67, 0x61, 0x6d, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x06, 0x67, 0x61, 0x6d, 0x65, 0x49, 0x64, 0x22, 0x87, 0x01, 0x0a, 0x04, 0x47, 0x61,
+	0x6d, 0x65, 0x12, 0x17, 0x0a, 0x07, 0x67, 0x61, 0x6d, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x06, 0x67, 0x61, 0x6d, 0x65, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x70,
+	0x69, 0x63, 0x6b, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0d, 0x52, 0x05, 0x70, 0x69, 0x63, 0x6b,
+	0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74, 0x54, 0x69, 0x6d, 0x65,
+	0x12, 0x19, 0x0a, 0x08, 0x65, 0x6e, 0x64, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x07, 0x65, 0x6e, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x22, 0xa3, 0x01, 0x0a, 0x10, 0x4c, 0x69, 0x73, 0x74, 0x47, 0x61, 0x6d, 0x65,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x67, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x70, 0x61, 0x67, 0x65, 0x12, 0x19, 0x0a, 0x08,
+	0x70, 0x65, 0x72, 0x5f, 0x70, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07,
+	0x70, 0x65, 0x72, 0x50, 0x61, 0x67, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x66, 0x72, 0x6f, 0x6d, 0x5f,
+	0x67, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x66, 0x72, 0x6f, 0x6d,
+	0x47, 0x61, 0x6d, 0x65, 0x12, 0x17, 0x0a, 0x07, 0x74, 0x6f, 0x5f, 0x67, 0x61, 0x6d, 0x65, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x74, 0x6f, 0x47, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a,
+	0x05, 0x73, 0x69, 0x6e, 0x63, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x73, 0x69,
+	0x6e, 0x63, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x75, 0x6e, 0x74, 0x69, 0x6c, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x05, 0x75, 0x6e, 0x74, 0x69, 0x6c, 0x22, 0x7d, 0x0a, 0x11, 0x4c, 0x69, 0x73,
+	0x74, 0x47, 0x61, 0x6d, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x23,
+	0x0a, 0x05, 0x67, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0d, 0x2e,
+	0x6b, 0x65, 0x6e, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x61, 0x6d, 0x65, 0x52, 0x05, 0x67, 0x61,
+	0x6d, 0x65, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x04, 0x70, 0x61, 0x67, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x70, 0x65, 0x72, 0x5f, 0x70,
+	0x61, 0x67, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x70, 0x65, 0x72, 0x50, 0x61,
+	0x67, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x22, 0x37, 0x0a, 0x11, 0x57, 0x61, 0x74, 0x63,
+	0x68, 0x47, 0x61, 0x6d, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x22, 0x0a,
+	0x0d, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x6c, 0x61, 0x73, 0x74, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x49,
+	0x64, 0x22, 0xbd, 0x01, 0x0a, 0x09, 0x47, 0x61, 0x6d, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12,
+	0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x02, 0x69, 0x64, 0x12,
+	0x2d, 0x0a, 0x08, 0x6e, 0x65, 0x77, 0x5f, 0x67, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x10, 0x2e, 0x6b, 0x65, 0x6e, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x4e, 0x65, 0x77, 0x47,
+	0x61, 0x6d, 0x65, 0x48, 0x00, 0x52, 0x07, 0x6e, 0x65, 0x77, 0x47, 0x61, 0x6d, 0x65, 0x12, 0x2d,
+	0x0a, 0x08, 0x6e, 0x65, 0x77, 0x5f, 0x70, 0x69, 0x63, 0x6b, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x10, 0x2e, 0x6b, 0x65, 0x6e, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x4e, 0x65, 0x77, 0x50, 0x69,
+	0x63, 0x6b, 0x48, 0x00, 0x52, 0x07, 0x6e, 0x65, 0x77, 0x50, 0x69, 0x63, 0x6b, 0x12, 0x39, 0x0a,
+	0x0c, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x5f, 0x67, 0x61, 0x6d, 0x65, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x6b, 0x65, 0x6e, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x75,
+	0x72, 0x72, 0x65, 0x6e, 0x74, 0x47, 0x61, 0x6d, 0x65, 0x48, 0x00, 0x52, 0x0b, 0x63, 0x75, 0x72,
+	0x72, 0x65, 0x6e, 0x74, 0x47, 0x61, 0x6d, 0x65, 0x42, 0x07, 0x0a, 0x05, 0x65, 0x76, 0x65, 0x6e,
+	0x74, 0x22, 0xb2, 0x01, 0x0a, 0x07, 0x4e, 0x65, 0x77, 0x47, 0x61, 0x6d, 0x65, 0x12, 0x17, 0x0a,
+	0x07, 0x67, 0x61, 0x6d, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06,
+	0x67, 0x61, 0x6d, 0x65, 0x49, 0x64, 0x12, 0x24, 0x0a, 0x0e, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x67,
+	0x61, 0x6d, 0x65, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c,
+	0x6e, 0x65, 0x78, 0x74, 0x47, 0x61, 0x6d, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x35, 0x0a, 0x17,
+	0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x5f, 0x67, 0x61, 0x6d, 0x65, 0x5f, 0x73, 0x74, 0x61,
+	0x72, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x14, 0x63,
+	0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x47, 0x61, 0x6d, 0x65, 0x53, 0x74, 0x61, 0x72, 0x74, 0x54,
+	0x69, 0x6d, 0x65, 0x12, 0x31, 0x0a, 0x15, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x5f, 0x67,
+	0x61, 0x6d, 0x65, 0x5f, 0x65, 0x6e, 0x64, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x12, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x47, 0x61, 0x6d, 0x65, 0x45,
+	0x6e, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x22, 0x1d, 0x0a, 0x07, 0x4e, 0x65, 0x77, 0x50, 0x69, 0x63,
+	0x6b, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x69, 0x63, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x04, 0x70, 0x69, 0x63, 0x6b, 0x22, 0x83, 0x02, 0x0a, 0x0b, 0x43, 0x75, 0x72, 0x72, 0x65, 0x6e,
+	0x74, 0x47, 0x61, 0x6d, 0x65, 0x12, 0x17, 0x0a, 0x07, 0x67, 0x61, 0x6d, 0x65, 0x5f, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x67, 0x61, 0x6d, 0x65, 0x49, 0x64, 0x12, 0x14,
+	0x0a, 0x05, 0x70, 0x68, 0x61, 0x73, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x70,
+	0x68, 0x61, 0x73, 0x65, 0x12, 0x24, 0x0a, 0x0e, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x67, 0x61, 0x6d,
+	0x65, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x6e, 0x65,
+	0x78, 0x74, 0x47, 0x61, 0x6d, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x35, 0x0a, 0x17, 0x63, 0x75,
+	0x72, 0x72, 0x65, 0x6e, 0x74, 0x5f, 0x67, 0x61, 0x6d, 0x65, 0x5f, 0x73, 0x74, 0x61, 0x72, 0x74,
+	0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x14, 0x63, 0x75, 0x72,
+	0x72, 0x65, 0x6e, 0x74, 0x47, 0x61, 0x6d, 0x65, 0x53, 0x74, 0x61, 0x72, 0x74, 0x54, 0x69, 0x6d,
+	0x65, 0x12, 0x31, 0x0a, 0x15, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x5f, 0x67, 0x61, 0x6d,
+	0x65, 0x5f, 0x65, 0x6e, 0x64, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x12, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x47, 0x61, 0x6d, 0x65, 0x45, 0x6e, 0x64,
+	0x54, 0x69, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x69, 0x63, 0x6b, 0x73, 0x18, 0x06, 0x20,
+	0x03, 0x28, 0x0d, 0x52, 0x05, 0x70, 0x69, 0x63, 0x6b, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x65,
+	0x72, 0x76, 0x65, 0x72, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x0a, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x54, 0x69, 0x6d, 0x65, 0x32, 0xb8, 0x02, 0x0a, 0x04,
+	0x4b, 0x65, 0x6e, 0x6f, 0x12, 0x35, 0x0a, 0x09, 0x50, 0x6c, 0x61, 0x63, 0x65, 0x43, 0x61, 0x72,
+	0x64, 0x12, 0x19, 0x2e, 0x6b, 0x65, 0x6e, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x6c, 0x61, 0x63,
+	0x65, 0x43, 0x61, 0x72, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0d, 0x2e, 0x6b,
+	0x65, 0x6e, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x72, 0x64, 0x12, 0x42, 0x0a, 0x09, 0x43,
+	0x68, 0x65, 0x63, 0x6b, 0x43, 0x61, 0x72, 0x64, 0x12, 0x19, 0x2e, 0x6b, 0x65, 0x6e, 0x6f, 0x2e,
+	0x76, 0x31, 0x2e, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x43, 0x61, 0x72, 0x64, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x6b, 0x65, 0x6e, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x68,
+	0x65, 0x63, 0x6b, 0x43, 0x61, 0x72, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x31, 0x0a, 0x07, 0x47, 0x65, 0x74, 0x47, 0x61, 0x6d, 0x65, 0x12, 0x17, 0x2e, 0x6b, 0x65, 0x6e,
+	0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x47, 0x61, 0x6d, 0x65, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x0d, 0x2e, 0x6b, 0x65, 0x6e, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x61,
+	0x6d, 0x65, 0x12, 0x42, 0x0a, 0x09, 0x4c, 0x69, 0x73, 0x74, 0x47, 0x61, 0x6d, 0x65, 0x73, 0x12,
+	0x19, 0x2e, 0x6b, 0x65, 0x6e, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x47, 0x61,
+	0x6d, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x6b, 0x65, 0x6e,
+	0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x47, 0x61, 0x6d, 0x65, 0x73, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3e, 0x0a, 0x0a, 0x57, 0x61, 0x74, 0x63, 0x68, 0x47,
+	0x61, 0x6d, 0x65, 0x73, 0x12, 0x1a, 0x2e, 0x6b, 0x65, 0x6e, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x57,
+	0x61, 0x74, 0x63, 0x68, 0x47, 0x61, 0x6d, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x12, 0x2e, 0x6b, 0x65, 0x6e, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x61, 0x6d, 0x65, 0x45,
+	0x76, 0x65, 0x6e, 0x74, 0x30, 0x01, 0x42, 0x0d, 0x5a, 0x0b, 0x6b, 0x65, 0x6e, 0x6f, 0x2f, 0x6b,
+	0x65, 0x6e, 0x6f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_keno_proto_rawDescOnce sync.Once
+	file_keno_proto_rawDescData = file_keno_proto_rawDesc
+)
+
+func file_keno_proto_rawDescGZIP() []byte {
+	file_keno_proto_rawDescOnce.Do(func() {
+		file_keno_proto_rawDescData = protoimpl.X.CompressGZIP(file_keno_proto_rawDescData)
+	})
+	return file_keno_proto_rawDescData
+}
+
+var file_keno_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_keno_proto_goTypes = []interface{}{
+	(*PlaceCardRequest)(nil),  // 0: keno.v1.PlaceCardRequest
+	(*Card)(nil),              // 1: keno.v1.Card
+	(*CheckCardRequest)(nil),  // 2: keno.v1.CheckCardRequest
+	(*CheckCardResponse)(nil), // 3: keno.v1.CheckCardResponse
+	(*CardGameResult)(nil),    // 4: keno.v1.CardGameResult
+	(*GetGameRequest)(nil),    // 5: keno.v1.GetGameRequest
+	(*Game)(nil),              // 6: keno.v1.Game
+	(*ListGamesRequest)(nil),  // 7: keno.v1.ListGamesRequest
+	(*ListGamesResponse)(nil), // 8: keno.v1.ListGamesResponse
+	(*WatchGamesRequest)(nil), // 9: keno.v1.WatchGamesRequest
+	(*GameEvent)(nil),         // 10: keno.v1.GameEvent
+	(*NewGame)(nil),           // 11: keno.v1.NewGame
+	(*NewPick)(nil),           // 12: keno.v1.NewPick
+	(*CurrentGame)(nil),       // 13: keno.v1.CurrentGame
+}
+var file_keno_proto_depIdxs = []int32{
+	4,  // 0: keno.v1.CheckCardResponse.games:type_name -> keno.v1.CardGameResult
+	6,  // 1: keno.v1.ListGamesResponse.games:type_name -> keno.v1.Game
+	11, // 2: keno.v1.GameEvent.new_game:type_name -> keno.v1.NewGame
+	12, // 3: keno.v1.GameEvent.new_pick:type_name -> keno.v1.NewPick
+	13, // 4: keno.v1.GameEvent.current_game:type_name -> keno.v1.CurrentGame
+	0,  // 5: keno.v1.Keno.PlaceCard:input_type -> keno.v1.PlaceCardRequest
+	2,  // 6: keno.v1.Keno.CheckCard:input_type -> keno.v1.CheckCardRequest
+	5,  // 7: keno.v1.Keno.GetGame:input_type -> keno.v1.GetGameRequest
+	7,  // 8: keno.v1.Keno.ListGames:input_type -> keno.v1.ListGamesRequest
+	9,  // 9: keno.v1.Keno.WatchGames:input_type -> keno.v1.WatchGamesRequest
+	1,  // 10: keno.v1.Keno.PlaceCard:output_type -> keno.v1.Card
+	3,  // 11: keno.v1.Keno.CheckCard:output_type -> keno.v1.CheckCardResponse
+	6,  // 12: keno.v1.Keno.GetGame:output_type -> keno.v1.Game
+	8,  // 13: keno.v1.Keno.ListGames:output_type -> keno.v1.ListGamesResponse
+	10, // 14: keno.v1.Keno.WatchGames:output_type -> keno.v1.GameEvent
+	10, // [10:15] is the sub-list for method output_type
+	5,  // [5:10] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_keno_proto_init() }
+func file_keno_proto_init() {
+	if File_keno_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_keno_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PlaceCardRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_keno_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Card); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_keno_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CheckCardRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_keno_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CheckCardResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_keno_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CardGameResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_keno_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetGameRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_keno_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Game); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_keno_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListGamesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_keno_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListGamesResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_keno_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WatchGamesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_keno_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GameEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_keno_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*NewGame); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_keno_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*NewPick); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_keno_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CurrentGame); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_keno_proto_msgTypes[10].OneofWrappers = []interface{}{
+		(*GameEvent_NewGame)(nil),
+		(*GameEvent_NewPick)(nil),
+		(*GameEvent_CurrentGame)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_keno_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   14,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_keno_proto_goTypes,
+		DependencyIndexes: file_keno_proto_depIdxs,
+		MessageInfos:      file_keno_proto_msgTypes,
+	}.Build()
+	File_keno_proto = out.File
+	file_keno_proto_rawDesc = nil
+	file_keno_proto_goTypes = nil
+	file_keno_proto_depIdxs = nil
+}
@@ -0,0 +1,303 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: keno.proto
+
+package kenopb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Keno_PlaceCard_FullMethodName  = "/keno.v1.Keno/PlaceCard"
+	Keno_CheckCard_FullMethodName  = "/keno.v1.Keno/CheckCard"
+	Keno_GetGame_FullMethodName    = "/keno.v1.Keno/GetGame"
+	Keno_ListGames_FullMethodName  = "/keno.v1.Keno/ListGames"
+	Keno_WatchGames_FullMethodName = "/keno.v1.Keno/WatchGames"
+)
+
+// KenoClient is the client API for Keno service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type KenoClient interface {
+	// PlaceCard places a card on the next game open for betting, following the
+	// same rules as POST /api/v1/picks. Retries are safe when the call has an
+	// `idempotency-key` in its metadata, they return the first call's card.
+	PlaceCard(ctx context.Context, in *PlaceCardRequest, opts ...grpc.CallOption) (*Card, error)
+	// CheckCard returns what a card has won, once all of its games are drawn.
+	CheckCard(ctx context.Context, in *CheckCardRequest, opts ...grpc.CallOption) (*CheckCardResponse, error)
+	// GetGame returns a game's drawn numbers, no authorization is needed.
+	GetGame(ctx context.Context, in *GetGameRequest, opts ...grpc.CallOption) (*Game, error)
+	// ListGames returns the games which have been drawn, newest first, a page
+	// at a time. No authorization is needed.
+	ListGames(ctx context.Context, in *ListGamesRequest, opts ...grpc.CallOption) (*ListGamesResponse, error)
+	// WatchGames streams the current game, starting with its state, then each
+	// new game and pick as they happen. No authorization is needed.
+	WatchGames(ctx context.Context, in *WatchGamesRequest, opts ...grpc.CallOption) (Keno_WatchGamesClient, error)
+}
+
+type kenoClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewKenoClient(cc grpc.ClientConnInterface) KenoClient {
+	return &kenoClient{cc}
+}
+
+func (c *kenoClient) PlaceCard(ctx context.Context, in *PlaceCardRequest, opts ...grpc.CallOption) (*Card, error) {
+	out := new(Card)
+	err := c.cc.Invoke(ctx, Keno_PlaceCard_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kenoClient) CheckCard(ctx context.Context, in *CheckCardRequest, opts ...grpc.CallOption) (*CheckCardResponse, error) {
+	out := new(CheckCardResponse)
+	err := c.cc.Invoke(ctx, Keno_CheckCard_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kenoClient) GetGame(ctx context.Context, in *GetGameRequest, opts ...grpc.CallOption) (*Game, error) {
+	out := new(Game)
+	err := c.cc.Invoke(ctx, Keno_GetGame_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kenoClient) ListGames(ctx context.Context, in *ListGamesRequest, opts ...grpc.CallOption) (*ListGamesResponse, error) {
+	out := new(ListGamesResponse)
+	err := c.cc.Invoke(ctx, Keno_ListGames_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kenoClient) WatchGames(ctx context.Context, in *WatchGamesRequest, opts ...grpc.CallOption) (Keno_WatchGamesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Keno_ServiceDesc.Streams[0], Keno_WatchGames_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &kenoWatchGamesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Keno_WatchGamesClient interface {
+	Recv() (*GameEvent, error)
+	grpc.ClientStream
+}
+
+type kenoWatchGamesClient struct {
+	grpc.ClientStream
+}
+
+func (x *kenoWatchGamesClient) Recv() (*GameEvent, error) {
+	m := new(GameEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// KenoServer is the server API for Keno service.
+// All implementations must embed UnimplementedKenoServer
+// for forward compatibility
+type KenoServer interface {
+	// PlaceCard places a card on the next game open for betting, following the
+	// same rules as POST /api/v1/picks. Retries are safe when the call has an
+	// `idempotency-key` in its metadata, they return the first call's card.
+	PlaceCard(context.Context, *PlaceCardRequest) (*Card, error)
+	// CheckCard returns what a card has won, once all of its games are drawn.
+	CheckCard(context.Context, *CheckCardRequest) (*CheckCardResponse, error)
+	// GetGame returns a game's drawn numbers, no authorization is needed.
+	GetGame(context.Context, *GetGameRequest) (*Game, error)
+	// ListGames returns the games which have been drawn, newest first, a page
+	// at a time. No authorization is needed.
+	ListGames(context.Context, *ListGamesRequest) (*ListGamesResponse, error)
+	// WatchGames streams the current game, starting with its state, then each
+	// new game and pick as they happen. No authorization is needed.
+	WatchGames(*WatchGamesRequest, Keno_WatchGamesServer) error
+	mustEmbedUnimplementedKenoServer()
+}
+
+// UnimplementedKenoServer must be embedded to have forward compatible implementations.
+type UnimplementedKenoServer struct {
+}
+
+func (UnimplementedKenoServer) PlaceCard(context.Context, *PlaceCardRequest) (*Card, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PlaceCard not implemented")
+}
+func (UnimplementedKenoServer) CheckCard(context.Context, *CheckCardRequest) (*CheckCardResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckCard not implemented")
+}
+func (UnimplementedKenoServer) GetGame(context.Context, *GetGameRequest) (*Game, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetGame not implemented")
+}
+func (UnimplementedKenoServer) ListGames(context.Context, *ListGamesRequest) (*ListGamesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListGames not implemented")
+}
+func (UnimplementedKenoServer) WatchGames(*WatchGamesRequest, Keno_WatchGamesServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchGames not implemented")
+}
+func (UnimplementedKenoServer) mustEmbedUnimplementedKenoServer() {}
+
+// UnsafeKenoServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to KenoServer will
+// result in compilation errors.
+type UnsafeKenoServer interface {
+	mustEmbedUnimplementedKenoServer()
+}
+
+func RegisterKenoServer(s grpc.ServiceRegistrar, srv KenoServer) {
+	s.RegisterService(&Keno_ServiceDesc, srv)
+}
+
+func _Keno_PlaceCard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlaceCardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KenoServer).PlaceCard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Keno_PlaceCard_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KenoServer).PlaceCard(ctx, req.(*PlaceCardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keno_CheckCard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckCardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KenoServer).CheckCard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Keno_CheckCard_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KenoServer).CheckCard(ctx, req.(*CheckCardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keno_GetGame_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetGameRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KenoServer).GetGame(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Keno_GetGame_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KenoServer).GetGame(ctx, req.(*GetGameRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keno_ListGames_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListGamesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KenoServer).ListGames(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Keno_ListGames_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KenoServer).ListGames(ctx, req.(*ListGamesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Keno_WatchGames_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchGamesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(KenoServer).WatchGames(m, &kenoWatchGamesServer{stream})
+}
+
+type Keno_WatchGamesServer interface {
+	Send(*GameEvent) error
+	grpc.ServerStream
+}
+
+type kenoWatchGamesServer struct {
+	grpc.ServerStream
+}
+
+func (x *kenoWatchGamesServer) Send(m *GameEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Keno_ServiceDesc is the grpc.ServiceDesc for Keno service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Keno_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "keno.v1.Keno",
+	HandlerType: (*KenoServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "PlaceCard",
+			Handler:    _Keno_PlaceCard_Handler,
+		},
+		{
+			MethodName: "CheckCard",
+			Handler:    _Keno_CheckCard_Handler,
+		},
+		{
+			MethodName: "GetGame",
+			Handler:    _Keno_GetGame_Handler,
+		},
+		{
+			MethodName: "ListGames",
+			Handler:    _Keno_ListGames_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchGames",
+			Handler:       _Keno_WatchGames_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "keno.proto",
+}
@@ -1,15 +1,25 @@
 package main
 
 import (
+	"keno/internal/achievements"
 	"keno/internal/api"
 	"keno/internal/db"
 	"keno/internal/engine"
+	"keno/internal/models"
+	"keno/internal/rpc"
+	"keno/internal/settlement"
+	"keno/internal/tenants"
+	"net"
 	"os"
+	"strings"
+	"time"
 
 	// Include Swagger docs in the project
 	_ "keno/docs"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"gorm.io/gorm"
 
 	log "github.com/sirupsen/logrus"
@@ -30,24 +40,128 @@ func main() {
 		panic(err)
 	}
 
+	// Sign ticket references and give one to any card which doesn't have one
+	if err := models.LoadTicketSecret(database, os.Getenv("KENO_TICKET_SECRET")); err != nil {
+		panic(err)
+	}
+	if err := models.BackfillTicketRefs(database); err != nil {
+		panic(err)
+	}
+
+	// Keep count of what each game could pay out as cards are placed
+	if err := models.BackfillGameLiabilities(database); err != nil {
+		panic(err)
+	}
+
+	// Keep running totals for the leaderboards as games are settled
+	if err := models.BackfillLeaderboardTotals(database, time.Now()); err != nil {
+		panic(err)
+	}
+
+	// Admins are given as a comma separated list of Discord user IDs
+	for _, admin := range strings.Split(os.Getenv("KENO_ADMINS"), ",") {
+		if admin = strings.TrimSpace(admin); admin != "" {
+			api.Admins = append(api.Admins, admin)
+		}
+	}
+
+	// Load the guilds sharing the backend
+	tenantsPath := os.Getenv("KENO_TENANTS")
+	if tenantsPath == "" {
+		tenantsPath = "tenants.json"
+	}
+	api.Tenants, err = tenants.LoadTenants(tenantsPath)
+	if err != nil {
+		panic(err)
+	}
+
+	// Load the betting limits
+	bettingPath := os.Getenv("KENO_BETTING")
+	if bettingPath == "" {
+		bettingPath = "betting.json"
+	}
+	if err := api.LoadBettingLimits(bettingPath); err != nil {
+		panic(err)
+	}
+
+	// Load the achievements config
+	achievementsPath := os.Getenv("KENO_ACHIEVEMENTS")
+	if achievementsPath == "" {
+		achievementsPath = "achievements.json"
+	}
+	achievementList, err := achievements.LoadAchievements(achievementsPath)
+	if err != nil {
+		panic(err)
+	}
+
 	// Setup the game engine
 	gameEngine := engine.SetupEngine(database)
+	gameEngine.AddGameStartHook(api.RenewSubscriptions(database, gameEngine))
+	gameEngine.AddBettingClosedHook(api.LockChallenges(database, gameEngine))
+
+	// Settle each game as it completes
+	settler := settlement.NewWorker(database)
+	gameEngine.AddGameCompleteHook(settler.GameComplete)
+	settler.AddSettledHook("notify_winners", api.NotifyWinners(gameEngine))
+	settler.AddSettledHook("pay_syndicates", api.PaySyndicates(database, gameEngine))
+	settler.AddSettledHook("resolve_challenges", api.ResolveChallenges(database, gameEngine))
+	settler.AddSettledHook("score_tournaments", api.ScoreTournaments(database, gameEngine))
+	settler.AddSettledHook("leaderboards", api.RefreshLeaderboards(database))
+	settler.AddSettledHook("achievements", achievements.NewChecker(database, achievementList, gameEngine.NotifyTenant).GameSettled)
+	go settler.Start(gameEngine.GetGameNumber())
 
-	// Run the API and Engine
-	go launchAPI(database, gameEngine)
+	// Run the APIs and Engine
+	go launchAPI(database, gameEngine, achievementList)
+	go launchGRPC(database, gameEngine)
 	gameEngine.StartLoop()
 }
 
+// launchGRPC serves the gRPC API alongside the REST one, for clients which
+// want typed calls. Calls carry the user's Discord token, so it's only served
+// over TLS, with the certificate and key from KENO_GRPC_CERT and
+// KENO_GRPC_KEY. Setting KENO_GRPC_INSECURE serves it without TLS instead,
+// for local development.
+func launchGRPC(database *gorm.DB, gameEngine *engine.Engine) {
+	opts := make([]grpc.ServerOption, 0)
+	certFile, keyFile := os.Getenv("KENO_GRPC_CERT"), os.Getenv("KENO_GRPC_KEY")
+	switch {
+	case certFile != "" && keyFile != "":
+		creds, err := credentials.NewServerTLSFromFile(certFile, keyFile)
+		if err != nil {
+			panic(err)
+		}
+		opts = append(opts, grpc.Creds(creds))
+	case os.Getenv("KENO_GRPC_INSECURE") != "":
+		log.WithField("src", "main.launchGRPC").Warn("Serving the gRPC API without TLS")
+	default:
+		log.WithField("src", "main.launchGRPC").Warn("No gRPC certificate set, not serving the gRPC API")
+		return
+	}
+
+	listener, err := net.Listen("tcp", ":9090")
+	if err != nil {
+		panic(err)
+	}
+
+	if err := rpc.NewServer(database, gameEngine, opts...).Serve(listener); err != nil {
+		panic(err)
+	}
+}
+
 // @title           			TAB Keno API
 // @version         			1.0
 // @description     			This is a sample server for TAB Keno API.
+// @description     			Requests are made in the default community unless the X-Guild-Id header names one of the guilds sharing the backend, which keeps its own cards, results and leaderboards.
+// @description     			Every response has an X-Request-Id header, which is kept from the request if it had one. Errors have a stable `code`, a `message`, the same `request_id` and, for invalid requests, `details` of each field which was wrong.
 // @host            			localhost:8080
-func launchAPI(database *gorm.DB, gameEngine *engine.Engine) {
+func launchAPI(database *gorm.DB, gameEngine *engine.Engine, achievementList []achievements.Achievement) {
 	gin.SetMode(gin.ReleaseMode)
 
 	r := gin.Default()
+	r.Use(api.RequestID)
 	r.Use(func(ctx *gin.Context) { ctx.Set(db.DbKey, database) })
 	r.Use(func(ctx *gin.Context) { ctx.Set(engine.EngineKey, gameEngine) })
+	r.Use(func(ctx *gin.Context) { ctx.Set(achievements.AchievementsKey, achievementList) })
 
 	v1 := r.Group("/api/v1")
 	{
@@ -55,10 +169,62 @@ func launchAPI(database *gorm.DB, gameEngine *engine.Engine) {
 		v1.Use(api.DiscordAuth)
 
 		// Protected API
-		v1.POST("/picks", api.PlacePicks)
+		v1.POST("/picks", api.Idempotent, api.PlacePicks)
+		v1.POST("/picks/batch", api.Idempotent, api.PlaceBatchPicks)
 		v1.GET("/check/:card_id", api.CheckCard)
+		v1.POST("/cancel/:card_id", api.CancelCard)
+		v1.POST("/rebet/:card_id", api.Idempotent, api.RebetCard)
+
+		// Favourites
+		v1.GET("/favourites", api.ListFavourites)
+		v1.POST("/favourites", api.SaveFavourite)
+		v1.DELETE("/favourites/:favourite_id", api.DeleteFavourite)
+		v1.POST("/favourites/:favourite_id/play", api.Idempotent, api.PlayFavourite)
+
+		// Subscriptions
+		v1.GET("/subscriptions", api.ListSubscriptions)
+		v1.POST("/subscriptions", api.Subscribe)
+		v1.DELETE("/subscriptions/:subscription_id", api.CancelSubscription)
+
+		// Syndicates
+		v1.GET("/syndicates", api.ListSyndicates)
+		v1.POST("/syndicates", api.Idempotent, api.StartSyndicate)
+		v1.GET("/syndicates/:syndicate_id", api.GetSyndicate)
+		v1.DELETE("/syndicates/:syndicate_id", api.CancelSyndicate)
+		v1.POST("/syndicates/:syndicate_id/shares", api.Idempotent, api.BuySyndicateShares)
+
+		// Challenges
+		v1.GET("/challenges", api.ListChallenges)
+		v1.POST("/challenges", api.Idempotent, api.CreateChallenge)
+		v1.GET("/challenges/:challenge_id", api.GetChallenge)
+		v1.DELETE("/challenges/:challenge_id", api.CancelChallenge)
+		v1.POST("/challenges/:challenge_id/accept", api.Idempotent, api.AcceptChallenge)
+
+		// Tournaments
+		v1.GET("/tournaments", api.ListTournaments)
+		v1.POST("/tournaments", api.RequireAdmin, api.CreateTournament)
+		v1.GET("/tournaments/:tournament_id/standings", api.GetStandings)
+		v1.POST("/tournaments/:tournament_id/register", api.RegisterForTournament)
+		v1.POST("/tournaments/:tournament_id/picks", api.Idempotent, api.PlaceTournamentPicks)
+
+		v1.POST("/stream-ticket", api.CreateStreamTicket)
+		v1.GET("/history", api.GetHistory)
+		v1.GET("/leaderboards/:board", api.GetLeaderboard)
+		v1.GET("/achievements", api.ListAchievements)
+		v1.GET("/achievements/me", api.GetUserAchievements)
+
+		// Responsible gambling
+		v1.GET("/limits", api.GetLimits)
+		v1.PUT("/limits", api.SetLimits)
+		v1.POST("/limits/cool-off", api.CoolOff)
+		v1.POST("/limits/self-exclude", api.SelfExclude)
 	}
 	r.GET("/api/v1/ws", api.GameStreamer)
+	r.GET("/api/v1/events", api.GameEvents)
+	r.GET("/api/v1/tickets/:ticket_ref", api.GetTicket)
+	r.GET("/api/v1/games", api.ListGames)
+	r.GET("/api/v1/games/current", api.GetCurrentGame)
+	r.GET("/api/v1/games/:game_id", api.GetGame)
 	r.GET("/api/v1/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	r.Run(":8080")
 }